@@ -0,0 +1,237 @@
+package myddlmaker
+
+import (
+	"strings"
+)
+
+// onlineDDL is the algorithm and the lock level that MySQL 8.0 uses for a change.
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html
+type onlineDDL struct {
+	// algorithm is INSTANT, INPLACE or COPY.
+	// It is empty if the change is not an ALTER TABLE statement.
+	algorithm string
+
+	// lock is DEFAULT, NONE or SHARED.
+	lock string
+
+	// rebuild reports whether the change rebuilds the table.
+	rebuild bool
+}
+
+var (
+	ddlInstant        = onlineDDL{algorithm: "INSTANT", lock: "DEFAULT"}
+	ddlInplace        = onlineDDL{algorithm: "INPLACE", lock: "NONE"}
+	ddlInplaceRebuild = onlineDDL{algorithm: "INPLACE", lock: "NONE", rebuild: true}
+	ddlInplaceShared  = onlineDDL{algorithm: "INPLACE", lock: "SHARED", rebuild: true}
+	ddlCopy           = onlineDDL{algorithm: "COPY", lock: "SHARED", rebuild: true}
+)
+
+// rank returns the cost of the change.
+func (d onlineDDL) rank() int {
+	switch d {
+	case ddlInstant:
+		return 1
+	case ddlInplace:
+		return 2
+	case ddlInplaceRebuild:
+		return 3
+	case ddlInplaceShared:
+		return 4
+	case ddlCopy:
+		return 5
+	}
+	return 0
+}
+
+func maxOnlineDDL(a, b onlineDDL) onlineDDL {
+	if a.rank() < b.rank() {
+		return b
+	}
+	return a
+}
+
+// clause returns the ALGORITHM and LOCK clause.
+func (d onlineDDL) clause() string {
+	if d.algorithm == "" {
+		return ""
+	}
+	return "ALGORITHM=" + d.algorithm + ", LOCK=" + d.lock
+}
+
+// onlineDDL predicts the algorithm that MySQL 8.0.29 or later uses for the change.
+// It is conservative, and it may choose a slower algorithm than MySQL.
+func (m *Maker) onlineDDL(c *change) onlineDDL {
+	switch c.kind {
+	case changeRenameColumn:
+		return ddlInstant
+	case changeTableOptions:
+		return tableOptionsOnlineDDL(c.options)
+	case changeDropForeignKey, changeDropCheck:
+		return ddlInplace
+	case changeDropIndex:
+		if c.sql == "DROP PRIMARY KEY" {
+			// dropping a primary key without adding a new one needs to copy the table.
+			return ddlCopy
+		}
+		return ddlInplace
+	case changeDropColumn:
+		if c.noInstant {
+			return ddlInplaceRebuild
+		}
+		return ddlInstant
+	case changeModifyColumn:
+		return m.modifyColumnOnlineDDL(c.oldTable, c.newTable, c.oldColumn, c.newColumn)
+	case changeAddColumn:
+		if c.newColumn.autoIncr {
+			return ddlInplaceShared
+		}
+		if c.newColumn.generated != "" && c.newColumn.stored {
+			// MySQL computes the values of the stored column for all rows.
+			return ddlCopy
+		}
+		if c.noInstant {
+			return ddlInplaceRebuild
+		}
+		return ddlInstant
+	case changeAddIndex:
+		switch {
+		case strings.HasPrefix(c.sql, "ADD PRIMARY KEY"), strings.HasPrefix(c.sql, "DROP PRIMARY KEY, ADD PRIMARY KEY"):
+			// replacing the primary key rebuilds the table in place.
+			return ddlInplaceRebuild
+		case strings.HasPrefix(c.sql, "ADD FULLTEXT"), strings.HasPrefix(c.sql, "ADD SPATIAL"):
+			return ddlInplaceShared
+		}
+		return ddlInplace
+	case changeAddForeignKey:
+		// INPLACE is supported only if foreign_key_checks is disabled.
+		return ddlCopy
+	case changeAddCheck:
+		// MySQL checks all rows with the table copy.
+		return ddlCopy
+	case changePartitionBy, changeRemovePartitioning:
+		// https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html#online-ddl-partitioning
+		return ddlCopy
+	}
+
+	// not an ALTER TABLE statement.
+	return onlineDDL{}
+}
+
+// compressedRowFormat reports whether InnoDB stores the table in the compressed row format.
+// KEY_BLOCK_SIZE implies ROW_FORMAT=COMPRESSED if ROW_FORMAT is omitted.
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html#online-ddl-column-operations
+func compressedRowFormat(table *table) bool {
+	if table.rowFormat != "" {
+		return strings.EqualFold(table.rowFormat, "COMPRESSED")
+	}
+	return table.keyBlockSize > 0
+}
+
+func tableOptionsOnlineDDL(opts []definition) onlineDDL {
+	ddl := ddlInplace
+	for _, opt := range opts {
+		switch opt.name {
+		case "ENGINE":
+			ddl = maxOnlineDDL(ddl, ddlCopy)
+		case "CHARSET", "COLLATE", "ROW_FORMAT", "KEY_BLOCK_SIZE":
+			ddl = maxOnlineDDL(ddl, ddlInplaceRebuild)
+		}
+	}
+	return ddl
+}
+
+func (m *Maker) modifyColumnOnlineDDL(from, to *table, old, new *column) onlineDDL {
+	if old.typ != new.typ || old.unsigned != new.unsigned || old.autoIncr != new.autoIncr || old.srid != new.srid ||
+		old.charset != new.charset || old.collate != new.collate ||
+		old.generated != new.generated || old.stored != new.stored ||
+		old.precision != new.precision || old.scale != new.scale {
+		return ddlCopy
+	}
+
+	if !equalStrings(old.values, new.values) {
+		// adding values to the end of the list is INSTANT if the storage size is not changed.
+		if len(old.values) > len(new.values) || !equalStrings(old.values, new.values[:len(old.values)]) ||
+			valuesStorageSize(old) != valuesStorageSize(new) {
+			return ddlCopy
+		}
+	}
+
+	ret := ddlInstant
+	if old.size != new.size {
+		// extending VARCHAR is in-place if the number of length bytes is not changed.
+		if (new.typ == "VARCHAR" || new.typ == "VARBINARY") && old.size < new.size &&
+			m.varcharLengthBytes(from, old) == m.varcharLengthBytes(to, new) {
+			ret = maxOnlineDDL(ret, ddlInplace)
+		} else {
+			return ddlCopy
+		}
+	}
+	if old.null != new.null {
+		ret = maxOnlineDDL(ret, ddlInplaceRebuild)
+	}
+	if old.comment != new.comment || old.invisible != new.invisible || old.onUpdate != new.onUpdate {
+		ret = maxOnlineDDL(ret, ddlInplace)
+	}
+	// changing only the default value is INSTANT.
+	return ret
+}
+
+// valuesStorageSize returns the number of bytes that store the values of ENUM and SET.
+func valuesStorageSize(col *column) int {
+	n := len(col.values)
+	if col.typ == "ENUM" {
+		if n < 256 {
+			return 1
+		}
+		return 2
+	}
+	switch size := (n + 7) / 8; size {
+	case 5, 6, 7:
+		return 8
+	default:
+		return size
+	}
+}
+
+// varcharLengthBytes returns the number of bytes that store the length of the column in the table.
+func (m *Maker) varcharLengthBytes(table *table, col *column) int {
+	size := col.size
+	if col.typ == "VARCHAR" {
+		charset, _ := columnCharset(table, col, m.config.DB)
+		size *= maxBytesPerChar(charset)
+	}
+	if size < 256 {
+		return 1
+	}
+	return 2
+}
+
+// maxBytesPerChar returns the maximum length of a character in the charset.
+// It returns 4, the length of utf8mb4, for unknown charsets.
+func maxBytesPerChar(charset string) int {
+	switch strings.ToLower(charset) {
+	case "ascii", "binary", "latin1", "latin2", "cp1250", "cp1251", "cp1256", "cp1257":
+		return 1
+	case "ucs2", "sjis", "cp932", "euckr", "gbk", "big5":
+		return 2
+	case "utf8", "utf8mb3", "ujis", "eucjpms":
+		return 3
+	}
+	return 4
+}
+
+// statement returns the SQL statement of the change.
+// If Config.OnlineDDL is enabled, the ALTER TABLE statements have ALGORITHM and LOCK clauses.
+func (m *Maker) statement(c *change) string {
+	stmt := c.statement()
+	if m.config.OnlineDDL {
+		if clause := m.onlineDDL(c).clause(); clause != "" {
+			if c.kind == changePartitionBy || c.kind == changeRemovePartitioning {
+				// partition_options must be the last of ALTER TABLE.
+				return "ALTER TABLE " + quote(c.table) + " " + clause + " " + c.sql
+			}
+			stmt += ", " + clause
+		}
+	}
+	return stmt
+}
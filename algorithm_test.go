@@ -0,0 +1,286 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMaker_modifyColumnOnlineDDL(t *testing.T) {
+	tests := []struct {
+		from *table
+		to   *table
+		db   *DBConfig
+		old  *column
+		new  *column
+		want onlineDDL
+	}{
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 191, def: "'John Doe'"},
+			want: ddlInstant,
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 191, comment: "the name"},
+			want: ddlInplace,
+		},
+		{
+			old:  &column{name: "updated_at", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)"},
+			new:  &column{name: "updated_at", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", onUpdate: "CURRENT_TIMESTAMP(6)"},
+			want: ddlInplace,
+		},
+		{
+			// the length is stored in 2 bytes in both columns.
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255},
+			want: ddlInplace,
+		},
+		{
+			// the length bytes change from 1 to 2.
+			old:  &column{name: "name", typ: "VARCHAR", size: 32, charset: "latin1"},
+			new:  &column{name: "name", typ: "VARCHAR", size: 300, charset: "latin1"},
+			want: ddlCopy,
+		},
+		{
+			// the length bytes of the utf8mb4 table change from 1 to 2 in the latin1 database.
+			from: &table{name: "user", charset: "utf8mb4"},
+			to:   &table{name: "user", charset: "utf8mb4"},
+			db:   &DBConfig{Charset: "latin1"},
+			old:  &column{name: "name", typ: "VARCHAR", size: 60},
+			new:  &column{name: "name", typ: "VARCHAR", size: 70},
+			want: ddlCopy,
+		},
+		{
+			// the length is stored in 1 byte in both columns of the latin1 database.
+			db:   &DBConfig{Charset: "latin1"},
+			old:  &column{name: "name", typ: "VARCHAR", size: 60},
+			new:  &column{name: "name", typ: "VARCHAR", size: 70},
+			want: ddlInplace,
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 191, null: true},
+			want: ddlInplaceRebuild,
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 191, charset: "latin1"},
+			want: ddlCopy,
+		},
+		{
+			old:  &column{name: "id", typ: "INTEGER"},
+			new:  &column{name: "id", typ: "BIGINT"},
+			want: ddlCopy,
+		},
+		{
+			// adding a value to the end of the list.
+			old:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive"}},
+			new:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive", "deleted"}},
+			want: ddlInstant,
+		},
+		{
+			// reordering the values.
+			old:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive"}},
+			new:  &column{name: "status", typ: "ENUM", values: []string{"inactive", "active"}},
+			want: ddlCopy,
+		},
+		{
+			// the storage size changes from 1 byte to 2 bytes.
+			old:  &column{name: "perms", typ: "SET", values: []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+			new:  &column{name: "perms", typ: "SET", values: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}},
+			want: ddlCopy,
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := New(&Config{DB: tt.db})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		from, to := tt.from, tt.to
+		if from == nil {
+			from = &table{}
+		}
+		if to == nil {
+			to = &table{}
+		}
+		got := m.modifyColumnOnlineDDL(from, to, tt.old, tt.new)
+		if got != tt.want {
+			t.Errorf("%v -> %v: got %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestTableOptionsOnlineDDL(t *testing.T) {
+	tests := []struct {
+		opts []definition
+		want onlineDDL
+	}{
+		{
+			opts: []definition{{name: "COMMENT", sql: "COMMENT='users'"}},
+			want: ddlInplace,
+		},
+		{
+			opts: []definition{{name: "COMMENT", sql: "COMMENT='users'"}, {name: "ROW_FORMAT", sql: "ROW_FORMAT=COMPRESSED"}},
+			want: ddlInplaceRebuild,
+		},
+		{
+			opts: []definition{{name: "ENGINE", sql: "ENGINE=MyISAM"}, {name: "ROW_FORMAT", sql: "ROW_FORMAT=COMPRESSED"}},
+			want: ddlCopy,
+		},
+	}
+
+	for i, tt := range tests {
+		got := tableOptionsOnlineDDL(tt.opts)
+		if got != tt.want {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestMaker_GenerateDiff_OnlineDDL(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&DiffUser1{}, &DiffEntry2{}, &DiffLog1{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{
+		OnlineDDL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&DiffUser2{}, &DiffEntry2{}, &DiffComment{}, &DiffLog2{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	want := "ALTER TABLE `user` DROP INDEX `idx_age`, ALGORITHM=INPLACE, LOCK=NONE;\n" +
+		"CREATE TABLE `comment` (\n" +
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+		"    `entry_id` INTEGER NOT NULL,\n" +
+		"    INDEX `idx_entry_id` (`entry_id`),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		");\n" +
+		"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NULL, ALGORITHM=INPLACE, LOCK=NONE;\n" +
+		"ALTER TABLE `user` ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`, ALGORITHM=INSTANT, LOCK=DEFAULT;\n" +
+		"ALTER TABLE `user` ADD UNIQUE `uniq_email` (`email`), ALGORITHM=INPLACE, LOCK=NONE;\n" +
+		"ALTER TABLE `log` ALGORITHM=COPY, LOCK=SHARED PARTITION BY HASH (`user_id`) PARTITIONS 4;\n" +
+		"ALTER TABLE `comment` ADD CONSTRAINT `fk_comment_entry` FOREIGN KEY (`entry_id`) REFERENCES `entry` (`id`), ALGORITHM=COPY, LOCK=SHARED;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+type OnlineDDLArticle1 struct {
+	ID   int32
+	Body string `ddl:",type=TEXT"`
+}
+
+func (*OnlineDDLArticle1) Table() string {
+	return "article"
+}
+
+func (*OnlineDDLArticle1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OnlineDDLArticle1) FullTextIndexes() []*FullTextIndex {
+	return []*FullTextIndex{
+		NewFullTextIndex("idx_body", "body"),
+	}
+}
+
+type OnlineDDLArticle2 struct {
+	ID    int32
+	Body  string `ddl:",type=TEXT"`
+	Title string
+}
+
+func (*OnlineDDLArticle2) Table() string {
+	return "article"
+}
+
+func (*OnlineDDLArticle2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OnlineDDLArticle2) FullTextIndexes() []*FullTextIndex {
+	return []*FullTextIndex{
+		NewFullTextIndex("idx_body", "body"),
+	}
+}
+
+type OnlineDDLArchive1 struct {
+	ID   int32
+	Body string
+}
+
+func (*OnlineDDLArchive1) Table() string {
+	return "archive"
+}
+
+func (*OnlineDDLArchive1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OnlineDDLArchive1) TableOptions() *TableOptions {
+	return NewTableOptions().RowFormat("COMPRESSED")
+}
+
+type OnlineDDLArchive2 struct {
+	ID int32
+}
+
+func (*OnlineDDLArchive2) Table() string {
+	return "archive"
+}
+
+func (*OnlineDDLArchive2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OnlineDDLArchive2) TableOptions() *TableOptions {
+	return NewTableOptions().RowFormat("COMPRESSED")
+}
+
+func TestMaker_GenerateDiff_OnlineDDLNoInstant(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&OnlineDDLArticle1{}, &OnlineDDLArchive1{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{
+		OnlineDDL:        true,
+		AllowDestructive: []string{"archive.body"},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OnlineDDLArticle2{}, &OnlineDDLArchive2{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// INSTANT is not available for the tables with full-text indexes or ROW_FORMAT=COMPRESSED.
+	want := "ALTER TABLE `archive` DROP COLUMN `body`, ALGORITHM=INPLACE, LOCK=NONE;\n" +
+		"ALTER TABLE `article` ADD COLUMN `title` VARCHAR(191) NOT NULL AFTER `body`, ALGORITHM=INPLACE, LOCK=NONE;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -56,3 +58,140 @@ type jsonMarker interface {
 // On Go 1.19, the reflect package can't handle generic types correctly.
 // However, it can handle a interface implemented by generic types.
 func (v JSON[T]) jsonMarker() { /* nothing to do */ }
+
+var _ driver.Valuer = Decimal{}
+var _ sql.Scanner = (*Decimal)(nil)
+
+// Decimal represents an exact decimal number for the MySQL DECIMAL type.
+// Its value is unscaled * 10^-scale.
+// The zero value is 0.
+type Decimal struct {
+	// unscaled is nil if the value is 0.
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal returns a new decimal number unscaled * 10^-scale.
+func NewDecimal(unscaled *big.Int, scale int) Decimal {
+	u := new(big.Int).Set(unscaled)
+	if scale < 0 {
+		u.Mul(u, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	return Decimal{
+		unscaled: u,
+		scale:    scale,
+	}
+}
+
+// ParseDecimal parses a decimal number, e.g. "-123.45".
+func ParseDecimal(s string) (Decimal, error) {
+	digits := s
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		digits = digits[1:]
+	}
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if integer == "" && fraction == "" || !isDigits(integer) || !isDigits(fraction) {
+		return Decimal{}, fmt.Errorf("myddlmaker: invalid decimal: %q", s)
+	}
+
+	u, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("myddlmaker: invalid decimal: %q", s)
+	}
+	if s[0] == '-' {
+		u.Neg(u)
+	}
+	return Decimal{
+		unscaled: u,
+		scale:    len(fraction),
+	}, nil
+}
+
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Unscaled returns the unscaled value of d.
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Rat returns d as a rational number.
+func (d Decimal) Rat() *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.Unscaled(), denom)
+}
+
+// String returns d in the decimal notation, e.g. "-123.45".
+func (d Decimal) String() string {
+	u := d.Unscaled()
+	digits := new(big.Int).Abs(u).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if u.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] interface.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements [database/sql/driver.Valuer] interface.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements [database/sql.Scanner] interface.
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	case int64:
+		*d = NewDecimal(big.NewInt(src), 0)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(src, 'f', -1, 64)
+	default:
+		return fmt.Errorf("myddlmaker: unsupported type: %T", src)
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -2,6 +2,7 @@ package myddlmaker
 
 import (
 	"context"
+	"math/big"
 	"reflect"
 	"testing"
 )
@@ -70,3 +71,108 @@ func TestJSONScan(t *testing.T) {
 		t.Errorf("unexpected result: %#v, want %s", obj0, data)
 	}
 }
+
+func TestDecimal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db, ok := setupDatabase(ctx, t)
+	if !ok {
+		return
+	}
+
+	ddl := "CREATE TABLE `foo` (`id` INTEGER, `price` DECIMAL(30,10), PRIMARY KEY (`id`))"
+	if _, err := db.ExecContext(ctx, ddl); err != nil {
+		t.Fatal(err)
+	}
+
+	// the value can't be represented by float64 exactly.
+	d0, err := ParseDecimal("12345678901234567890.0123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO `foo` (`id`, `price`) VALUES (?, ?)", 1, d0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d1 Decimal
+	row := db.QueryRowContext(ctx, "SELECT `price` FROM `foo` WHERE `id` = ?", 1)
+	if err := row.Scan(&d1); err != nil {
+		t.Fatal(err)
+	}
+	if d0.String() != d1.String() {
+		t.Errorf("result not match: got %s, want %s", d1, d0)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in       string
+		unscaled int64
+		scale    int
+		str      string
+	}{
+		{"0", 0, 0, "0"},
+		{"123", 123, 0, "123"},
+		{"-123.45", -12345, 2, "-123.45"},
+		{"+0.05", 5, 2, "0.05"},
+		{".5", 5, 1, "0.5"},
+		{"1.", 1, 0, "1"},
+		{"-0.001", -1, 3, "-0.001"},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+			continue
+		}
+		if d.Unscaled().Cmp(big.NewInt(tt.unscaled)) != 0 || d.Scale() != tt.scale {
+			t.Errorf("%q: got %s * 10^-%d, want %d * 10^-%d", tt.in, d.Unscaled(), d.Scale(), tt.unscaled, tt.scale)
+		}
+		if got := d.String(); got != tt.str {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.str)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e3", "abc"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("%q: want some error, but not", in)
+		}
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want string
+	}{
+		{[]byte("123.4500"), "123.4500"},
+		{"-0.5", "-0.5"},
+		{int64(42), "42"},
+		{float64(1.25), "1.25"},
+	}
+
+	for _, tt := range tests {
+		var d Decimal
+		if err := d.Scan(tt.src); err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.src, err)
+			continue
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.src, got, tt.want)
+		}
+	}
+
+	var zero Decimal
+	if got := zero.String(); got != "0" {
+		t.Errorf("zero value: got %q, want %q", got, "0")
+	}
+	if got := NewDecimal(big.NewInt(12), -2).String(); got != "1200" {
+		t.Errorf("negative scale: got %q, want %q", got, "1200")
+	}
+	if got := NewDecimal(big.NewInt(5), 3).Rat().String(); got != "1/200" {
+		t.Errorf("rat: got %q, want %q", got, "1/200")
+	}
+}
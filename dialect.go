@@ -0,0 +1,642 @@
+package myddlmaker
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Dialect is the SQL dialect of the database that the DDL Maker targets.
+// It maps Go types into column types, renders the definitions of columns, indexes and tables,
+// and rejects the features that the database doesn't support.
+//
+// The DDL Maker supports DialectMySQL, DialectMariaDB and DialectTiDB.
+// Dialect is sealed: its methods are unexported, so the packages other than myddlmaker can't implement it.
+type Dialect interface {
+	// String returns the name of the database.
+	String() string
+
+	// mapType sets the column type for the Go type typ into col.
+	// It returns false if typ is unknown.
+	mapType(col *column, typ reflect.Type) (bool, error)
+
+	// generateColumnDefinition writes the column definition.
+	generateColumnDefinition(w io.Writer, col *column)
+
+	// generateIndexDefinition writes the definition of the index.
+	generateIndexDefinition(w io.Writer, idx *Index)
+
+	// generateUniqueIndexDefinition writes the definition of the unique index.
+	generateUniqueIndexDefinition(w io.Writer, idx *UniqueIndex)
+
+	// generateFullTextIndexDefinition writes the definition of the full-text index.
+	generateFullTextIndexDefinition(w io.Writer, idx *FullTextIndex)
+
+	// generateSpatialIndexDefinition writes the definition of the spatial index.
+	generateSpatialIndexDefinition(w io.Writer, idx *SpatialIndex)
+
+	// tableOptions returns the table options after the closing parenthesis of CREATE TABLE.
+	// The names of the definitions are the names of the options, e.g. ENGINE and ROW_FORMAT.
+	tableOptions(table *table, db *DBConfig) []definition
+
+	// validate reports the features that the database doesn't support.
+	validate(v *validator)
+}
+
+var (
+	// DialectMySQL is the dialect of MySQL 8.0.
+	// It is the default dialect.
+	DialectMySQL Dialect = mysqlDialect{}
+
+	// DialectMariaDB is the dialect of MariaDB 10.6 or later.
+	DialectMariaDB Dialect = mariaDBDialect{}
+
+	// DialectTiDB is the dialect of TiDB 7.5 or later.
+	DialectTiDB Dialect = tidbDialect{}
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) String() string {
+	return "MySQL"
+}
+
+func (mysqlDialect) mapType(col *column, typ reflect.Type) (bool, error) {
+	// the named string types that have the permitted values are ENUM or SET.
+	switch v := reflect.New(typ).Interface().(type) {
+	case enumValues:
+		if typ.Kind() != reflect.String {
+			return false, fmt.Errorf("myddlmaker: %s implements EnumValues, but it is not a string type", typ.String())
+		}
+		col.typ = "ENUM"
+		col.values = v.EnumValues()
+		return true, nil
+	case setValues:
+		if typ.Kind() != reflect.String {
+			return false, fmt.Errorf("myddlmaker: %s implements SetValues, but it is not a string type", typ.String())
+		}
+		col.typ = "SET"
+		col.values = v.SetValues()
+		return true, nil
+	}
+
+	if typ.Implements(myddlmakerJSON) {
+		col.typ = "JSON"
+		return true, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		col.typ = "TINYINT"
+		col.size = 1
+	case reflect.Int8:
+		col.typ = "TINYINT"
+	case reflect.Int16:
+		col.typ = "SMALLINT"
+	case reflect.Int32:
+		col.typ = "INTEGER"
+	case reflect.Int64:
+		col.typ = "BIGINT"
+	case reflect.Uint8:
+		col.typ = "TINYINT"
+		col.unsigned = true
+	case reflect.Uint16:
+		col.typ = "SMALLINT"
+		col.unsigned = true
+	case reflect.Uint32:
+		col.typ = "INTEGER"
+		col.unsigned = true
+	case reflect.Uint64:
+		col.typ = "BIGINT"
+		col.unsigned = true
+	case reflect.Float32:
+		col.typ = "FLOAT"
+	case reflect.Float64:
+		col.typ = "DOUBLE"
+	case reflect.String:
+		col.typ = "VARCHAR"
+		col.size = 191
+	case reflect.Slice:
+		if typ == jsonRawMessageType {
+			col.typ = "JSON"
+		} else if typ.Elem().Kind() == reflect.Uint8 {
+			col.typ = "VARBINARY"
+			col.size = 767
+		} else {
+			return false, nil
+		}
+	case reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			col.typ = "BINARY"
+			col.size = typ.Len()
+		} else {
+			return false, nil
+		}
+	case reflect.Struct:
+		switch typ {
+		case timeType:
+			col.typ = "DATETIME"
+			col.size = 6
+		case nullTimeType:
+			col.typ = "DATETIME"
+			col.size = 6
+		case nullStringType:
+			col.typ = "VARCHAR"
+			col.size = 191
+		case nullBoolType:
+			col.typ = "TINYINT"
+			col.size = 1
+		case nullByteType:
+			col.typ = "TINYINT"
+			col.unsigned = true
+		case nullFloat64Type:
+			col.typ = "DOUBLE"
+		case nullInt16Type:
+			col.typ = "SMALLINT"
+		case nullInt32Type:
+			col.typ = "INTEGER"
+		case nullInt64Type:
+			col.typ = "BIGINT"
+		case decimalType:
+			// the default precision and scale of MySQL.
+			col.typ = "DECIMAL"
+			col.precision = 10
+			col.scale = 0
+		default:
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (mysqlDialect) generateColumnDefinition(w io.Writer, col *column) {
+	writeColumnDefinition(w, col, true)
+}
+
+// writeColumnDefinition writes the column definition.
+// If nullability is false, NULL and NOT NULL are omitted.
+func writeColumnDefinition(w io.Writer, col *column, nullability bool) {
+	io.WriteString(w, quote(col.name))
+	io.WriteString(w, " ")
+	io.WriteString(w, col.typ)
+	if col.size != 0 {
+		fmt.Fprintf(w, "(%d)", col.size)
+	}
+	if col.typ == "ENUM" || col.typ == "SET" {
+		io.WriteString(w, formatValues(col.values))
+	}
+	if col.precision > 0 {
+		fmt.Fprintf(w, "(%d,%d)", col.precision, col.scale)
+	}
+	if col.charset != "" {
+		io.WriteString(w, " CHARACTER SET ")
+		io.WriteString(w, col.charset)
+	}
+	if col.collate != "" {
+		io.WriteString(w, " COLLATE ")
+		io.WriteString(w, col.collate)
+	}
+	if col.unsigned {
+		io.WriteString(w, " UNSIGNED")
+	}
+	if col.generated != "" {
+		// https://dev.mysql.com/doc/refman/8.0/en/create-table-generated-columns.html
+		io.WriteString(w, " AS (")
+		io.WriteString(w, col.generated)
+		if col.stored {
+			io.WriteString(w, ") STORED")
+		} else {
+			io.WriteString(w, ") VIRTUAL")
+		}
+	}
+	if nullability {
+		if col.null {
+			io.WriteString(w, " NULL")
+		} else {
+			io.WriteString(w, " NOT NULL")
+		}
+	}
+	if col.def != "" {
+		io.WriteString(w, " DEFAULT ")
+		io.WriteString(w, col.def)
+	}
+	if col.onUpdate != "" {
+		io.WriteString(w, " ON UPDATE ")
+		io.WriteString(w, col.onUpdate)
+	}
+	if col.invisible {
+		// https://dev.mysql.com/doc/refman/8.0/en/invisible-columns.html
+		io.WriteString(w, " INVISIBLE")
+	}
+	if col.autoIncr {
+		io.WriteString(w, " AUTO_INCREMENT")
+	}
+	if col.comment != "" {
+		io.WriteString(w, " COMMENT ")
+		io.WriteString(w, stringQuote(col.comment))
+	}
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/invisible-indexes.html
+func (mysqlDialect) generateIndexDefinition(w io.Writer, idx *Index) {
+	writeIndexDefinition(w, idx, "INVISIBLE")
+}
+
+func (mysqlDialect) generateUniqueIndexDefinition(w io.Writer, idx *UniqueIndex) {
+	writeUniqueIndexDefinition(w, idx, "INVISIBLE")
+}
+
+func (mysqlDialect) generateFullTextIndexDefinition(w io.Writer, idx *FullTextIndex) {
+	writeFullTextIndexDefinition(w, idx, "INVISIBLE")
+}
+
+func (mysqlDialect) generateSpatialIndexDefinition(w io.Writer, idx *SpatialIndex) {
+	writeSpatialIndexDefinition(w, idx, "INVISIBLE")
+}
+
+func (mysqlDialect) tableOptions(table *table, db *DBConfig) []definition {
+	var opts []definition
+	engine := table.engine
+	if db != nil {
+		engine = withDefault(engine, db.Engine)
+	}
+	charset, collate := tableCharset(table, db)
+	if engine != "" {
+		opts = append(opts, definition{name: "ENGINE", sql: "ENGINE=" + engine})
+	}
+	if charset != "" {
+		opts = append(opts, definition{name: "CHARSET", sql: "DEFAULT CHARACTER SET=" + charset})
+	}
+	if collate != "" {
+		opts = append(opts, definition{name: "COLLATE", sql: "DEFAULT COLLATE=" + collate})
+	}
+	if table.rowFormat != "" {
+		opts = append(opts, definition{name: "ROW_FORMAT", sql: "ROW_FORMAT=" + table.rowFormat})
+	}
+	if table.keyBlockSize > 0 {
+		opts = append(opts, definition{name: "KEY_BLOCK_SIZE", sql: fmt.Sprintf("KEY_BLOCK_SIZE=%d", table.keyBlockSize)})
+	}
+	if table.autoIncrement > 0 {
+		opts = append(opts, definition{name: "AUTO_INCREMENT", sql: fmt.Sprintf("AUTO_INCREMENT=%d", table.autoIncrement)})
+	}
+	if table.statsPersistent != "" {
+		opts = append(opts, definition{name: "STATS_PERSISTENT", sql: "STATS_PERSISTENT=" + table.statsPersistent})
+	}
+	if table.comment != "" {
+		opts = append(opts, definition{name: "COMMENT", sql: "COMMENT=" + stringQuote(table.comment)})
+	}
+	return opts
+}
+
+func (d mysqlDialect) validate(v *validator) {
+	for _, seq := range v.Sequences {
+		v.SaveErrorf("sequence %q: SEQUENCE is not supported by %s", seq.name, d)
+	}
+	validateTiDBOnly(v)
+}
+
+// validateTiDBOnly reports the features that only TiDB supports.
+func validateTiDBOnly(v *validator) {
+	for _, table := range v.tables {
+		for _, col := range table.columns {
+			if col.autoRandom {
+				v.SaveErrorf("table %q, column %q: auto_random is supported only by TiDB", table.name, col.name)
+			}
+		}
+		if table.primaryKey != nil && table.primaryKey.clustered != "" {
+			v.SaveErrorf("table %q: %s primary keys are supported only by TiDB", table.name, table.primaryKey.clustered)
+		}
+		if table.shardRowIDBits != 0 {
+			v.SaveErrorf("table %q: SHARD_ROW_ID_BITS is supported only by TiDB", table.name)
+		}
+	}
+}
+
+// mariaDBDialect is the dialect of MariaDB.
+// MariaDB shares most of the syntax with MySQL.
+// JSON is an alias of LONGTEXT with the JSON_VALID check in MariaDB,
+// so the features that depend on the binary JSON type of MySQL are not available.
+type mariaDBDialect struct {
+	mysqlDialect
+}
+
+func (mariaDBDialect) String() string {
+	return "MariaDB"
+}
+
+func (d mariaDBDialect) mapType(col *column, typ reflect.Type) (bool, error) {
+	ok, err := d.mysqlDialect.mapType(col, typ)
+	if ok && col.typ == "JSON" {
+		// MariaDB stores JSON documents as LONGTEXT with the JSON_VALID check.
+		// https://mariadb.com/kb/en/json-data-type/
+		col.typ = "LONGTEXT"
+		col.charset = "utf8mb4"
+		col.collate = "utf8mb4_bin"
+		col.json = true
+	}
+	return ok, err
+}
+
+func (d mariaDBDialect) generateColumnDefinition(w io.Writer, col *column) {
+	// MariaDB rejects NULL and NOT NULL of the generated columns.
+	// https://mariadb.com/kb/en/generated-columns/
+	writeColumnDefinition(w, col, col.generated == "")
+	if col.json {
+		io.WriteString(w, " CHECK (JSON_VALID(")
+		io.WriteString(w, quote(col.name))
+		io.WriteString(w, "))")
+	}
+}
+
+// https://mariadb.com/kb/en/ignored-indexes/
+func (mariaDBDialect) generateIndexDefinition(w io.Writer, idx *Index) {
+	writeIndexDefinition(w, idx, "IGNORED")
+}
+
+func (mariaDBDialect) generateUniqueIndexDefinition(w io.Writer, idx *UniqueIndex) {
+	writeUniqueIndexDefinition(w, idx, "IGNORED")
+}
+
+func (mariaDBDialect) generateFullTextIndexDefinition(w io.Writer, idx *FullTextIndex) {
+	writeFullTextIndexDefinition(w, idx, "IGNORED")
+}
+
+func (mariaDBDialect) generateSpatialIndexDefinition(w io.Writer, idx *SpatialIndex) {
+	writeSpatialIndexDefinition(w, idx, "IGNORED")
+}
+
+func (d mariaDBDialect) validate(v *validator) {
+	v.validateSequences()
+	validateTiDBOnly(v)
+
+	for _, table := range v.tables {
+		for _, col := range table.columns {
+			if hasJSONOperator(col.generated) {
+				v.SaveErrorf("table %q, column %q: the JSON operators -> and ->> are not supported by %s, use JSON_VALUE or JSON_EXTRACT instead", table.name, col.name, d)
+			}
+		}
+		for _, idx := range table.indexes {
+			if hasFunctionalKeyPart(idx.keyParts) {
+				v.SaveErrorf("table %q, index %q: functional key parts are not supported by %s, index a generated column instead", table.name, idx.name, d)
+			}
+		}
+		for _, idx := range table.uniqueIndexes {
+			if hasFunctionalKeyPart(idx.keyParts) {
+				v.SaveErrorf("table %q, unique index %q: functional key parts are not supported by %s, index a generated column instead", table.name, idx.name, d)
+			}
+		}
+		for _, idx := range table.multiValuedIndexes {
+			v.SaveErrorf("table %q, multi-valued index %q: multi-valued indexes are not supported by %s", table.name, idx.name, d)
+		}
+		for _, chk := range table.checks {
+			if chk.notEnforced {
+				v.SaveErrorf("table %q, check constraint %q: NOT ENFORCED is not supported by %s", table.name, chk.name, d)
+			}
+			if hasJSONOperator(chk.expr) {
+				v.SaveErrorf("table %q, check constraint %q: the JSON operators -> and ->> are not supported by %s, use JSON_VALUE or JSON_EXTRACT instead", table.name, chk.name, d)
+			}
+		}
+	}
+}
+
+// tidbDialect is the dialect of TiDB.
+// TiDB is compatible with the MySQL protocol and syntax,
+// but it has its own features for the distributed storage, and lacks some features of MySQL.
+// https://docs.pingcap.com/tidb/stable/mysql-compatibility
+type tidbDialect struct {
+	mysqlDialect
+}
+
+func (tidbDialect) String() string {
+	return "TiDB"
+}
+
+func (d tidbDialect) generateColumnDefinition(w io.Writer, col *column) {
+	d.mysqlDialect.generateColumnDefinition(w, col)
+	if col.autoRandom {
+		// https://docs.pingcap.com/tidb/stable/auto-random
+		io.WriteString(w, " AUTO_RANDOM")
+		if col.shardBits > 0 {
+			fmt.Fprintf(w, "(%d)", col.shardBits)
+		}
+	}
+}
+
+func (d tidbDialect) tableOptions(table *table, db *DBConfig) []definition {
+	opts := d.mysqlDialect.tableOptions(table, db)
+	if table.shardRowIDBits > 0 {
+		// https://docs.pingcap.com/tidb/stable/shard-row-id-bits
+		opts = append(opts, definition{name: "SHARD_ROW_ID_BITS", sql: fmt.Sprintf("SHARD_ROW_ID_BITS=%d", table.shardRowIDBits)})
+	}
+	return opts
+}
+
+// maxShardBits is the maximum number of the shard bits of AUTO_RANDOM and SHARD_ROW_ID_BITS.
+const maxShardBits = 15
+
+func (d tidbDialect) validate(v *validator) {
+	v.validateSequences()
+
+	for _, table := range v.tables {
+		for _, col := range table.columns {
+			if isSpatialType(col.typ) {
+				v.SaveErrorf("table %q, column %q: spatial type %s is not supported by %s", table.name, col.name, col.typ, d)
+			}
+			if col.invisible {
+				v.SaveErrorf("table %q, column %q: invisible columns are not supported by %s", table.name, col.name, d)
+			}
+			if col.autoRandom {
+				d.validateAutoRandom(v, table, col)
+			}
+		}
+		for _, idx := range table.spatialIndexes {
+			v.SaveErrorf("table %q, spatial index %q: spatial indexes are not supported by %s", table.name, idx.name, d)
+		}
+		for _, idx := range table.fullTextIndexes {
+			v.SaveErrorf("table %q, fulltext index %q: full-text indexes are not supported by %s", table.name, idx.name, d)
+		}
+
+		if table.shardRowIDBits != 0 {
+			if table.shardRowIDBits < 0 || table.shardRowIDBits > maxShardBits {
+				v.SaveErrorf("table %q: SHARD_ROW_ID_BITS must be between 0 and %d, but got %d", table.name, maxShardBits, table.shardRowIDBits)
+			}
+			if hasClusteredPrimaryKey(table) {
+				v.SaveErrorf("table %q: SHARD_ROW_ID_BITS is not available for tables with clustered primary keys", table.name)
+			}
+		}
+	}
+}
+
+// validateAutoRandom validates the AUTO_RANDOM column.
+// https://docs.pingcap.com/tidb/stable/auto-random#restrictions
+func (d tidbDialect) validateAutoRandom(v *validator, table *table, col *column) {
+	if col.typ != "BIGINT" {
+		v.SaveErrorf("table %q, column %q: auto_random is available only for BIGINT", table.name, col.name)
+	}
+	if col.shardBits < 0 || col.shardBits > maxShardBits {
+		v.SaveErrorf("table %q, column %q: the shard bits of auto_random must be between 1 and %d, but got %d", table.name, col.name, maxShardBits, col.shardBits)
+	}
+	pk := table.primaryKey
+	if pk == nil || len(pk.columns) == 0 || pk.columns[0] != col.name {
+		v.SaveErrorf("table %q, column %q: auto_random column must be the first column of the primary key", table.name, col.name)
+		return
+	}
+	if pk.clustered == "NONCLUSTERED" {
+		v.SaveErrorf("table %q, column %q: auto_random is not available for NONCLUSTERED primary keys", table.name, col.name)
+	}
+}
+
+// hasClusteredPrimaryKey reports whether TiDB stores the rows of the table in the order of the primary key.
+// TiDB clusters the primary keys of AUTO_RANDOM columns even if CLUSTERED is omitted.
+func hasClusteredPrimaryKey(table *table) bool {
+	if table.primaryKey == nil {
+		return false
+	}
+	if table.primaryKey.clustered != "" {
+		return table.primaryKey.clustered == "CLUSTERED"
+	}
+	for _, col := range table.columns {
+		if col.autoRandom {
+			return true
+		}
+	}
+	return false
+}
+
+// isSpatialType reports whether typ is a spatial data type.
+// https://dev.mysql.com/doc/refman/8.0/en/spatial-type-overview.html
+func isSpatialType(typ string) bool {
+	switch typ {
+	case "GEOMETRY", "POINT", "LINESTRING", "POLYGON",
+		"MULTIPOINT", "MULTILINESTRING", "MULTIPOLYGON", "GEOMETRYCOLLECTION", "GEOMCOLLECTION":
+		return true
+	}
+	return false
+}
+
+// hasJSONOperator reports whether expr uses the JSON operators -> or ->>.
+// The operators in string literals are ignored.
+func hasJSONOperator(expr string) bool {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return strings.Contains(expr, "->")
+	}
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].kind == tokenSymbol && tokens[i].val == "-" &&
+			tokens[i+1].kind == tokenSymbol && tokens[i+1].val == ">" && tokens[i].end == tokens[i+1].pos {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFunctionalKeyPart(parts []*keyPart) bool {
+	for _, part := range parts {
+		if part.column == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeKeyParts writes the key parts of the index.
+func writeKeyParts(w io.Writer, parts []*keyPart) {
+	for i, part := range parts {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		if part.column != "" {
+			io.WriteString(w, quote(part.column))
+			if part.length > 0 {
+				fmt.Fprintf(w, "(%d)", part.length)
+			}
+		} else {
+			// functional key parts are enclosed with parentheses.
+			io.WriteString(w, "(")
+			io.WriteString(w, part.expr)
+			io.WriteString(w, ")")
+		}
+		if part.desc {
+			io.WriteString(w, " DESC")
+		}
+	}
+}
+
+// writeIndexDefinition writes the definition of the index.
+// invisible is the index option that hides the index from the optimizer.
+func writeIndexDefinition(w io.Writer, idx *Index, invisible string) {
+	io.WriteString(w, "INDEX ")
+	io.WriteString(w, quote(idx.name))
+	io.WriteString(w, " (")
+	writeKeyParts(w, idx.keyParts)
+	io.WriteString(w, ")")
+	if idx.invisible {
+		io.WriteString(w, " ")
+		io.WriteString(w, invisible)
+	}
+	if idx.comment != "" {
+		io.WriteString(w, " COMMENT ")
+		io.WriteString(w, stringQuote(idx.comment))
+	}
+}
+
+// writeUniqueIndexDefinition writes the definition of the unique index.
+// invisible is the index option that hides the index from the optimizer.
+func writeUniqueIndexDefinition(w io.Writer, idx *UniqueIndex, invisible string) {
+	io.WriteString(w, "UNIQUE ")
+	io.WriteString(w, quote(idx.name))
+	io.WriteString(w, " (")
+	writeKeyParts(w, idx.keyParts)
+	io.WriteString(w, ")")
+	if idx.invisible {
+		io.WriteString(w, " ")
+		io.WriteString(w, invisible)
+	}
+	if idx.comment != "" {
+		io.WriteString(w, " COMMENT ")
+		io.WriteString(w, stringQuote(idx.comment))
+	}
+}
+
+// writeFullTextIndexDefinition writes the definition of the full-text index.
+// invisible is the index option that hides the index from the optimizer.
+func writeFullTextIndexDefinition(w io.Writer, idx *FullTextIndex, invisible string) {
+	io.WriteString(w, "FULLTEXT INDEX ")
+	io.WriteString(w, quote(idx.name))
+	io.WriteString(w, " (")
+	io.WriteString(w, quote(idx.column))
+	io.WriteString(w, ")")
+	if idx.invisible {
+		io.WriteString(w, " ")
+		io.WriteString(w, invisible)
+	}
+	if idx.parser != "" {
+		io.WriteString(w, " WITH PARSER ")
+		io.WriteString(w, idx.parser)
+	}
+	if idx.comment != "" {
+		io.WriteString(w, " COMMENT ")
+		io.WriteString(w, stringQuote(idx.comment))
+	}
+}
+
+// writeSpatialIndexDefinition writes the definition of the spatial index.
+// invisible is the index option that hides the index from the optimizer.
+func writeSpatialIndexDefinition(w io.Writer, idx *SpatialIndex, invisible string) {
+	io.WriteString(w, "SPATIAL INDEX ")
+	io.WriteString(w, quote(idx.name))
+	io.WriteString(w, " (")
+	io.WriteString(w, quote(idx.column))
+	io.WriteString(w, ")")
+	if idx.invisible {
+		io.WriteString(w, " ")
+		io.WriteString(w, invisible)
+	}
+	if idx.comment != "" {
+		io.WriteString(w, " COMMENT ")
+		io.WriteString(w, stringQuote(idx.comment))
+	}
+}
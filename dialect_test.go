@@ -0,0 +1,508 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type MariaDBOrder struct {
+	ID         int64  `ddl:",default=NEXT VALUE FOR seq_order"`
+	Code       string `ddl:",invisible"`
+	Detail     JSON[[]int64]
+	CodeLength int32  `ddl:",as=(CHAR_LENGTH(code))"`
+	CodeUpper  string `ddl:",as=(UPPER(code)),stored"`
+}
+
+func (*MariaDBOrder) Table() string {
+	return "order"
+}
+
+func (*MariaDBOrder) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*MariaDBOrder) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_code", "code").Invisible(),
+	}
+}
+
+type MariaDBUnsupported struct {
+	ID     int32
+	Email  string
+	Tags   JSON[[]string]
+	Price  int32
+	TagsID string `ddl:",as=tags->'$.id'"`
+}
+
+func (*MariaDBUnsupported) Table() string {
+	return "unsupported"
+}
+
+func (*MariaDBUnsupported) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*MariaDBUnsupported) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_email", "(LOWER(email))"),
+	}
+}
+
+func (*MariaDBUnsupported) MultiValuedIndexes() []*MultiValuedIndex {
+	return []*MultiValuedIndex{
+		NewMultiValuedIndex("idx_tags", "tags", "CHAR(64)"),
+	}
+}
+
+func (*MariaDBUnsupported) Checks() []*CheckConstraint {
+	return []*CheckConstraint{
+		NewCheck("chk_price", "`price` >= 0").NotEnforced(),
+		NewCheck("chk_arrow", "email <> '->'"),
+	}
+}
+
+type TiDBUser struct {
+	ID   int64 `ddl:",auto_random=5"`
+	Name string
+}
+
+func (*TiDBUser) Table() string {
+	return "user"
+}
+
+func (*TiDBUser) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").Clustered()
+}
+
+type TiDBLog struct {
+	ID      int64
+	Message string
+}
+
+func (*TiDBLog) Table() string {
+	return "log"
+}
+
+func (*TiDBLog) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").NonClustered()
+}
+
+func (*TiDBLog) TableOptions() *TableOptions {
+	return NewTableOptions().ShardRowIDBits(4)
+}
+
+type TiDBUnsupported struct {
+	ID     int64  `ddl:",auto_random"`
+	Secret string `ddl:",invisible"`
+	Point  string `ddl:",type=GEOMETRY"`
+	Serial int32  `ddl:",auto_random=16"`
+}
+
+func (*TiDBUnsupported) Table() string {
+	return "unsupported"
+}
+
+func (*TiDBUnsupported) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").NonClustered()
+}
+
+func (*TiDBUnsupported) SpatialIndexes() []*SpatialIndex {
+	return []*SpatialIndex{
+		NewSpatialIndex("idx_point", "point"),
+	}
+}
+
+func (*TiDBUnsupported) FullTextIndexes() []*FullTextIndex {
+	return []*FullTextIndex{
+		NewFullTextIndex("idx_secret", "secret"),
+	}
+}
+
+func (*TiDBUnsupported) TableOptions() *TableOptions {
+	return NewTableOptions().ShardRowIDBits(16)
+}
+
+type TiDBEvent1 struct {
+	ID   int64
+	Name string
+}
+
+func (*TiDBEvent1) Table() string {
+	return "event"
+}
+
+func (*TiDBEvent1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").NonClustered()
+}
+
+type TiDBEvent2 struct {
+	ID   int64
+	Name string
+}
+
+func (*TiDBEvent2) Table() string {
+	return "event"
+}
+
+func (*TiDBEvent2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").Clustered()
+}
+
+type TiDBEvent3 struct {
+	ID   int64
+	Name string
+}
+
+func (*TiDBEvent3) Table() string {
+	return "event_log"
+}
+
+func (*TiDBEvent3) RenamedFrom() []string {
+	return []string{"event"}
+}
+
+func (*TiDBEvent3) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id").Clustered()
+}
+
+func TestMaker_Generate_MariaDB(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		Dialect: DialectMariaDB,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddSequences(NewSequence("seq_order").Start(1000).Increment(10).MinValue(1000).Cache(20))
+	m.AddStructs(&MariaDBOrder{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "SET foreign_key_checks=0;\n\n" +
+		"DROP SEQUENCE IF EXISTS `seq_order`;\n\n" +
+		"CREATE SEQUENCE `seq_order` START WITH 1000 INCREMENT BY 10 MINVALUE 1000 CACHE 20;\n\n" +
+		"\n" +
+		"DROP TABLE IF EXISTS `order`;\n\n" +
+		"CREATE TABLE `order` (\n" +
+		"    `id` BIGINT NOT NULL DEFAULT NEXT VALUE FOR seq_order,\n" +
+		"    `code` VARCHAR(191) NOT NULL INVISIBLE,\n" +
+		"    `detail` LONGTEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (JSON_VALID(`detail`)),\n" +
+		"    `code_length` INTEGER AS (CHAR_LENGTH(code)) VIRTUAL,\n" +
+		"    `code_upper` VARCHAR(191) AS (UPPER(code)) STORED,\n" +
+		"    INDEX `idx_code` (`code`) IGNORED,\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+		"SET foreign_key_checks=1;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_Generate_MariaDBBootstrap(t *testing.T) {
+	m, err := New(&Config{
+		Dialect:              DialectMariaDB,
+		Bootstrap:            true,
+		KeepForeignKeyChecks: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddSequences(NewSequence("seq_order").Cycle())
+	m.AddStructs(&Foo1{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE SEQUENCE IF NOT EXISTS `seq_order` CYCLE;\n\n" +
+		"CREATE TABLE IF NOT EXISTS `foo1` (\n" +
+		"    `id` INTEGER NOT NULL,\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		");\n\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_Generate_TiDB(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		Dialect: DialectTiDB,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddSequences(NewSequence("seq_log"))
+	m.AddStructs(&TiDBUser{}, &TiDBLog{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "SET foreign_key_checks=0;\n\n" +
+		"DROP SEQUENCE IF EXISTS `seq_log`;\n\n" +
+		"CREATE SEQUENCE `seq_log`;\n\n" +
+		"\n" +
+		"DROP TABLE IF EXISTS `user`;\n\n" +
+		"CREATE TABLE `user` (\n" +
+		"    `id` BIGINT NOT NULL AUTO_RANDOM(5),\n" +
+		"    `name` VARCHAR(191) NOT NULL,\n" +
+		"    PRIMARY KEY (`id`) CLUSTERED\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+		"\n" +
+		"DROP TABLE IF EXISTS `log`;\n\n" +
+		"CREATE TABLE `log` (\n" +
+		"    `id` BIGINT NOT NULL,\n" +
+		"    `message` VARCHAR(191) NOT NULL,\n" +
+		"    PRIMARY KEY (`id`) NONCLUSTERED\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin SHARD_ROW_ID_BITS=4;\n\n" +
+		"SET foreign_key_checks=1;\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+	}
+
+	// AUTO_RANDOM generates the values of the primary key.
+	buf.Reset()
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO `user` (`name`) VALUES"; !strings.Contains(buf.String(), want) {
+		t.Errorf("want %q in the generated code, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMaker_GenerateDiff_TiDB(t *testing.T) {
+	tests := []struct {
+		old  []any
+		new  []any
+		want string
+	}{
+		{
+			// switch NONCLUSTERED to CLUSTERED.
+			old:  []any{&TiDBEvent1{}},
+			new:  []any{&TiDBEvent2{}},
+			want: "ALTER TABLE `event` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`) CLUSTERED;\n",
+		},
+		{
+			// the renamed table keeps CLUSTERED.
+			old:  []any{&TiDBEvent2{}},
+			new:  []any{&TiDBEvent3{}},
+			want: "RENAME TABLE `event` TO `event_log`;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		old, err := New(&Config{
+			Dialect: DialectTiDB,
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		old.AddStructs(tt.old...)
+		schema, err := old.Schema()
+		if err != nil {
+			t.Fatalf("failed to parse the old schema: %v", err)
+		}
+
+		m, err := New(&Config{
+			Dialect: DialectTiDB,
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddStructs(tt.new...)
+
+		var buf bytes.Buffer
+		if err := m.GenerateDiff(&buf, schema); err != nil {
+			t.Fatalf("failed to generate diff: %v", err)
+		}
+		if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+			t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+		}
+	}
+}
+
+func TestMaker_GenerateDiff_Sequences(t *testing.T) {
+	tests := []struct {
+		old  []*Sequence
+		new  []*Sequence
+		want string
+	}{
+		{
+			old:  nil,
+			new:  []*Sequence{NewSequence("seq_order").Start(1000).Increment(10)},
+			want: "CREATE SEQUENCE `seq_order` START WITH 1000 INCREMENT BY 10;\n",
+		},
+		{
+			old:  []*Sequence{NewSequence("seq_order")},
+			new:  []*Sequence{NewSequence("seq_order").Increment(10).Cycle()},
+			want: "ALTER SEQUENCE `seq_order` INCREMENT BY 10 CYCLE;\n",
+		},
+		{
+			// the default values are equivalent to the omitted options.
+			old:  []*Sequence{NewSequence("seq_order").Start(1).MinValue(1).MaxValue(9223372036854775806).Cache(1000)},
+			new:  []*Sequence{NewSequence("seq_order")},
+			want: "",
+		},
+		{
+			old:  []*Sequence{NewSequence("seq_order")},
+			new:  nil,
+			want: "DROP SEQUENCE `seq_order`;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := New(&Config{
+			Dialect:          DialectMariaDB,
+			AllowDestructive: []string{"seq_order"},
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddSequences(tt.new...)
+
+		var buf bytes.Buffer
+		if err := m.GenerateDiff(&buf, &Schema{sequences: tt.old}); err != nil {
+			t.Fatalf("failed to generate diff: %v", err)
+		}
+		if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+			t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+		}
+	}
+}
+
+func TestParseSQL_MariaDB(t *testing.T) {
+	m, err := New(&Config{
+		Dialect: DialectMariaDB,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddSequences(NewSequence("seq_order").Start(1000).Increment(10).MinValue(1000).Cache(20).Cycle())
+	m.AddStructs(&MariaDBOrder{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSQL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the parsed schema has the same sequences and the same JSON columns.
+	buf.Reset()
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected diff:\n%s", buf.String())
+	}
+}
+
+func TestMaker_Generate_DialectError(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		structs   []any
+		sequences []*Sequence
+		want      []string
+	}{
+		{
+			dialect:   DialectMySQL,
+			structs:   []any{&Foo1{}},
+			sequences: []*Sequence{NewSequence("seq_foo")},
+			want: []string{
+				`sequence "seq_foo": SEQUENCE is not supported by MySQL`,
+			},
+		},
+		{
+			dialect: DialectMariaDB,
+			structs: []any{&Foo1{}},
+			sequences: []*Sequence{
+				NewSequence("foo1"),
+				NewSequence("seq_foo"),
+				NewSequence("seq_foo"),
+				NewSequence("seq_range").MinValue(10).MaxValue(1),
+				NewSequence("seq_start").Start(0).MinValue(1),
+			},
+			want: []string{
+				`sequence "foo1": duplicated name of table: "foo1"`,
+				`duplicated name of sequence: "seq_foo"`,
+				`sequence "seq_range": MINVALUE 10 must be less than MAXVALUE 1`,
+				`sequence "seq_start": START WITH 0 is less than MINVALUE 1`,
+			},
+		},
+		{
+			dialect: DialectMySQL,
+			structs: []any{&TiDBUser{}, &TiDBLog{}},
+			want: []string{
+				`table "user", column "id": auto_random is supported only by TiDB`,
+				`table "user": CLUSTERED primary keys are supported only by TiDB`,
+				`table "log": NONCLUSTERED primary keys are supported only by TiDB`,
+				`table "log": SHARD_ROW_ID_BITS is supported only by TiDB`,
+			},
+		},
+		{
+			dialect: DialectTiDB,
+			structs: []any{&TiDBUnsupported{}},
+			want: []string{
+				`table "unsupported", column "id": auto_random is not available for NONCLUSTERED primary keys`,
+				`table "unsupported", column "secret": invisible columns are not supported by TiDB`,
+				`table "unsupported", column "point": spatial type GEOMETRY is not supported by TiDB`,
+				`table "unsupported", column "serial": auto_random is available only for BIGINT`,
+				`table "unsupported", column "serial": the shard bits of auto_random must be between 1 and 15, but got 16`,
+				`table "unsupported", column "serial": auto_random column must be the first column of the primary key`,
+				`table "unsupported", spatial index "idx_point": spatial indexes are not supported by TiDB`,
+				`table "unsupported", fulltext index "idx_secret": full-text indexes are not supported by TiDB`,
+				`table "unsupported": SHARD_ROW_ID_BITS must be between 0 and 15, but got 16`,
+			},
+		},
+		{
+			dialect: DialectMariaDB,
+			structs: []any{&MariaDBUnsupported{}},
+			want: []string{
+				`table "unsupported", column "tags_id": the JSON operators -> and ->> are not supported by MariaDB, use JSON_VALUE or JSON_EXTRACT instead`,
+				`table "unsupported", index "idx_email": functional key parts are not supported by MariaDB, index a generated column instead`,
+				`table "unsupported", multi-valued index "idx_tags": multi-valued indexes are not supported by MariaDB`,
+				`table "unsupported", check constraint "chk_price": NOT ENFORCED is not supported by MariaDB`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := New(&Config{
+			Dialect: tt.dialect,
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddStructs(tt.structs...)
+		m.AddSequences(tt.sequences...)
+
+		var buf bytes.Buffer
+		err = m.Generate(&buf)
+		var errs *validationError
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: unexpected error: %v", tt.dialect, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, errs.errs); diff != "" {
+			t.Errorf("%s: unexpected errors (-want/+got):\n%s", tt.dialect, diff)
+		}
+	}
+}
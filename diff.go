@@ -0,0 +1,587 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+)
+
+// changeKind is a kind of schema changes.
+// The changes are applied in the order of changeKind,
+// so constraints are dropped before the columns they depend on and added after them.
+type changeKind int
+
+const (
+	changeRenameTable changeKind = iota
+	changeRenameColumn
+	changeDropForeignKey
+	changeDropCheck
+	changeDropIndex
+	changeDropTable
+	changeDropSequence
+	changeCreateSequence
+	changeAlterSequence
+	changeCreateTable
+	changeTableOptions
+	changeRemovePartitioning
+	changeDropColumn
+	changeModifyColumn
+	changeAddColumn
+	changeAddIndex
+	changePartitionBy // every unique key must include the partitioning columns, so it follows the indexes.
+	changeAddForeignKey
+	changeAddCheck
+)
+
+// change is a change of the schema.
+type change struct {
+	kind changeKind
+
+	// table is the name of the table, or the name of the sequence for the sequence changes.
+	table string
+
+	// name is the name of the column, the index, or the constraint.
+	name string
+
+	// oldColumn and newColumn are the column definitions before and after the change.
+	// They are available for the column changes.
+	oldColumn *column
+	newColumn *column
+
+	// oldTable and newTable are the tables before and after the change.
+	// They are available for changeModifyColumn, to resolve the inherited character sets.
+	oldTable *table
+	newTable *table
+
+	// noInstant reports that MySQL can't add or drop the columns of the table instantly.
+	// It is available for changeAddColumn and changeDropColumn.
+	noInstant bool
+
+	// options are the changed table options.
+	// They are available for changeTableOptions.
+	options []definition
+
+	// sql is the whole statement for changeRenameTable, changeDropTable, changeCreateTable and the sequence changes,
+	// and the clause of ALTER TABLE for others.
+	sql string
+}
+
+// statement returns the SQL statement without the trailing semicolon.
+func (c *change) statement() string {
+	if !isAlterTable(c) {
+		return c.sql
+	}
+	return "ALTER TABLE " + quote(c.table) + " " + c.sql
+}
+
+// GenerateDiff writes the SQL statements that migrate the old schema to the current one.
+// Unlike Generate, it doesn't drop the existing tables,
+// and it uses ALTER TABLE statements to change them.
+// If old is nil, it is treated as an empty schema.
+//
+// It refuses the changes that lose data, unless they are allowed by Config.AllowDestructive.
+// Use GenerateReport to see which changes lose data.
+func (m *Maker) GenerateDiff(w io.Writer, old *Schema) error {
+	var buf bytes.Buffer
+	changes, err := m.diffSchema(old)
+	if err != nil {
+		return err
+	}
+	if err := m.checkDestructive(changes); err != nil {
+		return err
+	}
+	m.writeChanges(&buf, changes)
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// diffSchema returns the changes from the old schema to the current one.
+func (m *Maker) diffSchema(old *Schema) ([]*change, error) {
+	if err := m.parse(); err != nil {
+		return nil, err
+	}
+
+	if old == nil {
+		old = &Schema{}
+	}
+	cur := m.currentSchema()
+	r, err := findRenames(old.tables, cur.tables)
+	if err != nil {
+		return nil, err
+	}
+	return m.diff(old, cur, r), nil
+}
+
+// currentSchema returns the schema of the parsed structs and the sequences.
+// Unlike Schema, it shares the slices with m.
+func (m *Maker) currentSchema() *Schema {
+	return &Schema{
+		tables:    m.tables,
+		sequences: m.sequences,
+	}
+}
+
+// diff returns the changes from the schema old to the schema cur.
+// The tables and the columns in r are renamed instead of dropped and re-created.
+func (m *Maker) diff(old, cur *Schema, r *renames) []*change {
+	changes, from := r.apply(old.tables)
+	to := cur.tables
+	changes = append(changes, m.diffSequences(old.sequences, cur.sequences)...)
+
+	fromMap := make(map[string]*table, len(from))
+	for _, table := range from {
+		fromMap[table.name] = table
+	}
+	toMap := make(map[string]*table, len(to))
+	for _, table := range to {
+		toMap[table.name] = table
+	}
+
+	for _, table := range from {
+		if _, ok := toMap[table.name]; ok {
+			continue
+		}
+
+		// drop the foreign key constraints first,
+		// the order of DROP TABLE statements doesn't matter.
+		for _, fk := range table.foreignKeys {
+			changes = append(changes, &change{
+				kind:  changeDropForeignKey,
+				table: table.name,
+				name:  fk.name,
+				sql:   "DROP FOREIGN KEY " + quote(fk.name),
+			})
+		}
+		changes = append(changes, &change{
+			kind:  changeDropTable,
+			table: table.name,
+			sql:   "DROP TABLE " + quote(table.name),
+		})
+	}
+
+	for _, table := range to {
+		if old, ok := fromMap[table.name]; ok {
+			changes = append(changes, m.diffTable(old, table)...)
+			continue
+		}
+
+		// the referenced tables may not exist yet,
+		// so the foreign key constraints are added after all tables are created.
+		tmp := *table // shallow copy
+		tmp.foreignKeys = nil
+		var buf strings.Builder
+		m.generateCreateTable(&buf, &tmp, false)
+		changes = append(changes, &change{
+			kind:  changeCreateTable,
+			table: table.name,
+			sql:   buf.String(),
+		})
+		for _, fk := range table.foreignKeys {
+			changes = append(changes, &change{
+				kind:  changeAddForeignKey,
+				table: table.name,
+				name:  fk.name,
+				sql:   "ADD " + m.foreignKeyDefinition(fk),
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].kind < changes[j].kind
+	})
+	return changes
+}
+
+// diffSequences returns the changes from the sequences from to the sequences to.
+func (m *Maker) diffSequences(from, to []*Sequence) []*change {
+	var changes []*change
+	fromMap := make(map[string]*Sequence, len(from))
+	for _, seq := range from {
+		fromMap[seq.name] = seq
+	}
+	toMap := make(map[string]*Sequence, len(to))
+	for _, seq := range to {
+		toMap[seq.name] = seq
+	}
+
+	for _, seq := range from {
+		if _, ok := toMap[seq.name]; ok {
+			continue
+		}
+		changes = append(changes, &change{
+			kind:  changeDropSequence,
+			table: seq.name,
+			sql:   "DROP SEQUENCE " + quote(seq.name),
+		})
+	}
+	for _, seq := range to {
+		old, ok := fromMap[seq.name]
+		if !ok {
+			var buf strings.Builder
+			m.generateCreateSequence(&buf, seq, false)
+			changes = append(changes, &change{
+				kind:  changeCreateSequence,
+				table: seq.name,
+				sql:   buf.String(),
+			})
+			continue
+		}
+		if opts := alterSequenceOptions(old, seq); len(opts) > 0 {
+			changes = append(changes, &change{
+				kind:  changeAlterSequence,
+				table: seq.name,
+				sql:   "ALTER SEQUENCE " + quote(seq.name) + " " + strings.Join(opts, " "),
+			})
+		}
+	}
+	return changes
+}
+
+// diffTable returns the changes from the table from to the table to.
+// They must have the same name.
+func (m *Maker) diffTable(from, to *table) []*change {
+	var changes []*change
+
+	// table options
+	if opts := m.diffTableOptions(from, to); len(opts) > 0 {
+		sqls := make([]string, 0, len(opts))
+		for _, opt := range opts {
+			sqls = append(sqls, opt.sql)
+		}
+		changes = append(changes, &change{
+			kind:    changeTableOptions,
+			table:   to.name,
+			options: opts,
+			sql:     strings.Join(sqls, " "),
+		})
+	}
+
+	// columns
+	// MySQL can't add or drop the columns instantly if the table has ROW_FORMAT=COMPRESSED or full-text indexes.
+	// The table options are changed before the columns, and the full-text indexes are dropped before them and added after them.
+	noInstant := compressedRowFormat(to) || (len(from.fullTextIndexes) > 0 && len(to.fullTextIndexes) > 0)
+	fromColumns := make(map[string]*column, len(from.columns))
+	for _, col := range from.columns {
+		fromColumns[col.name] = col
+	}
+	toColumns := make(map[string]*column, len(to.columns))
+	for _, col := range to.columns {
+		toColumns[col.name] = col
+	}
+	for _, col := range from.columns {
+		if _, ok := toColumns[col.name]; !ok {
+			changes = append(changes, &change{
+				kind:      changeDropColumn,
+				table:     to.name,
+				name:      col.name,
+				oldColumn: col,
+				noInstant: noInstant,
+				sql:       "DROP COLUMN " + quote(col.name),
+			})
+		}
+	}
+	for i, col := range to.columns {
+		old, ok := fromColumns[col.name]
+		if !ok {
+			pos := " FIRST"
+			if i > 0 {
+				pos = " AFTER " + quote(to.columns[i-1].name)
+			}
+			changes = append(changes, &change{
+				kind:      changeAddColumn,
+				table:     to.name,
+				name:      col.name,
+				newColumn: col,
+				noInstant: noInstant,
+				sql:       "ADD COLUMN " + m.columnDefinition(col) + pos,
+			})
+			continue
+		}
+		if def := m.columnDefinition(col); def != m.columnDefinition(old) {
+			changes = append(changes, &change{
+				kind:      changeModifyColumn,
+				table:     to.name,
+				name:      col.name,
+				oldColumn: old,
+				newColumn: col,
+				oldTable:  from,
+				newTable:  to,
+				sql:       "MODIFY COLUMN " + def,
+			})
+		}
+	}
+
+	// primary key
+	if def := m.primaryKeyDefinition(to.primaryKey); def != m.primaryKeyDefinition(from.primaryKey) {
+		hasPrimaryKey := from.primaryKey != nil && len(from.primaryKey.columns) > 0
+		switch {
+		case hasPrimaryKey && def != "":
+			// MySQL rejects DROP PRIMARY KEY alone if the primary key has an AUTO_INCREMENT column,
+			// so the primary key is replaced in one statement.
+			changes = append(changes, &change{
+				kind:  changeAddIndex,
+				table: to.name,
+				sql:   "DROP PRIMARY KEY, ADD " + def,
+			})
+		case hasPrimaryKey:
+			changes = append(changes, &change{
+				kind:  changeDropIndex,
+				table: to.name,
+				sql:   "DROP PRIMARY KEY",
+			})
+		case def != "":
+			changes = append(changes, &change{
+				kind:  changeAddIndex,
+				table: to.name,
+				sql:   "ADD " + def,
+			})
+		}
+	}
+
+	// indexes
+	fromIndexes := m.indexDefinitions(from)
+	toIndexes := m.indexDefinitions(to)
+	implicit := make(map[string]bool)
+	for _, idx := range from.indexes {
+		implicit[idx.name] = idx.implicit
+	}
+	for _, idx := range fromIndexes {
+		def, ok := findDefinition(toIndexes, idx.name)
+		if !ok && implicit[idx.name] {
+			// the index that MySQL created for the foreign key is not a part of the model.
+			continue
+		}
+		if !ok || def != idx.sql {
+			changes = append(changes, &change{
+				kind:  changeDropIndex,
+				table: to.name,
+				name:  idx.name,
+				sql:   "DROP INDEX " + quote(idx.name),
+			})
+		}
+	}
+	for _, idx := range toIndexes {
+		if def, ok := findDefinition(fromIndexes, idx.name); !ok || def != idx.sql {
+			changes = append(changes, &change{
+				kind:  changeAddIndex,
+				table: to.name,
+				name:  idx.name,
+				sql:   "ADD " + idx.sql,
+			})
+		}
+	}
+
+	// foreign keys
+	fromFKs := make([]definition, 0, len(from.foreignKeys))
+	for _, fk := range from.foreignKeys {
+		fromFKs = append(fromFKs, definition{name: fk.name, sql: m.foreignKeyDefinition(fk)})
+	}
+	toFKs := make([]definition, 0, len(to.foreignKeys))
+	for _, fk := range to.foreignKeys {
+		toFKs = append(toFKs, definition{name: fk.name, sql: m.foreignKeyDefinition(fk)})
+	}
+	for _, fk := range fromFKs {
+		if def, ok := findDefinition(toFKs, fk.name); !ok || def != fk.sql {
+			changes = append(changes, &change{
+				kind:  changeDropForeignKey,
+				table: to.name,
+				name:  fk.name,
+				sql:   "DROP FOREIGN KEY " + quote(fk.name),
+			})
+		}
+	}
+	for _, fk := range toFKs {
+		if def, ok := findDefinition(fromFKs, fk.name); !ok || def != fk.sql {
+			changes = append(changes, &change{
+				kind:  changeAddForeignKey,
+				table: to.name,
+				name:  fk.name,
+				sql:   "ADD " + fk.sql,
+			})
+		}
+	}
+
+	// partitions
+	if def := m.partitionsDefinition(to.partitions); def != m.partitionsDefinition(from.partitions) {
+		if to.partitions == nil {
+			changes = append(changes, &change{
+				kind:  changeRemovePartitioning,
+				table: to.name,
+				sql:   "REMOVE PARTITIONING",
+			})
+		} else {
+			changes = append(changes, &change{
+				kind:  changePartitionBy,
+				table: to.name,
+				sql:   def,
+			})
+		}
+	}
+
+	// check constraints
+	fromChecks := make([]definition, 0, len(from.checks))
+	for _, chk := range from.checks {
+		fromChecks = append(fromChecks, definition{name: chk.name, sql: m.checkDefinition(chk)})
+	}
+	toChecks := make([]definition, 0, len(to.checks))
+	for _, chk := range to.checks {
+		toChecks = append(toChecks, definition{name: chk.name, sql: m.checkDefinition(chk)})
+	}
+	for _, chk := range fromChecks {
+		if def, ok := findDefinition(toChecks, chk.name); !ok || def != chk.sql {
+			changes = append(changes, &change{
+				kind:  changeDropCheck,
+				table: to.name,
+				name:  chk.name,
+				sql:   "DROP CHECK " + quote(chk.name),
+			})
+		}
+	}
+	for _, chk := range toChecks {
+		if def, ok := findDefinition(fromChecks, chk.name); !ok || def != chk.sql {
+			changes = append(changes, &change{
+				kind:  changeAddCheck,
+				table: to.name,
+				name:  chk.name,
+				sql:   "ADD " + chk.sql,
+			})
+		}
+	}
+
+	return changes
+}
+
+// resetTableOptions are the table options that restore the default values.
+// The other options, e.g. ENGINE and DEFAULT CHARACTER SET, are kept as they are if they are removed.
+var resetTableOptions = map[string]string{
+	"ROW_FORMAT":        "ROW_FORMAT=DEFAULT",
+	"KEY_BLOCK_SIZE":    "KEY_BLOCK_SIZE=0",
+	"STATS_PERSISTENT":  "STATS_PERSISTENT=DEFAULT",
+	"COMMENT":           "COMMENT=''",
+	"SHARD_ROW_ID_BITS": "SHARD_ROW_ID_BITS=0",
+}
+
+// diffTableOptions returns the table options that are changed from the table from to the table to.
+// AUTO_INCREMENT is ignored, because it is the initial value of the counter, not a part of the schema.
+func (m *Maker) diffTableOptions(from, to *table) []definition {
+	fromOpts := m.config.Dialect.tableOptions(from, m.config.DB)
+	toOpts := m.config.Dialect.tableOptions(to, m.config.DB)
+
+	var opts []definition
+	for _, opt := range fromOpts {
+		if _, ok := findDefinition(toOpts, opt.name); ok {
+			continue
+		}
+		if reset, ok := resetTableOptions[opt.name]; ok {
+			opts = append(opts, definition{name: opt.name, sql: reset})
+		}
+	}
+	for _, opt := range toOpts {
+		if opt.name == "AUTO_INCREMENT" {
+			continue
+		}
+		if def, ok := findDefinition(fromOpts, opt.name); !ok || def != opt.sql {
+			opts = append(opts, opt)
+		}
+	}
+	return opts
+}
+
+// definition is a named definition of an index or a constraint.
+type definition struct {
+	name string
+	sql  string
+}
+
+func findDefinition(defs []definition, name string) (string, bool) {
+	for _, def := range defs {
+		if def.name == name {
+			return def.sql, true
+		}
+	}
+	return "", false
+}
+
+// indexDefinitions returns the definitions of the indexes in the table except the primary key.
+func (m *Maker) indexDefinitions(table *table) []definition {
+	var defs []definition
+	var buf strings.Builder
+	for _, idx := range table.indexes {
+		buf.Reset()
+		m.generateIndexDefinition(&buf, idx)
+		defs = append(defs, definition{name: idx.name, sql: buf.String()})
+	}
+	for _, idx := range table.uniqueIndexes {
+		buf.Reset()
+		m.generateUniqueIndexDefinition(&buf, idx)
+		defs = append(defs, definition{name: idx.name, sql: buf.String()})
+	}
+	for _, idx := range table.fullTextIndexes {
+		buf.Reset()
+		m.generateFullTextIndexDefinition(&buf, idx)
+		defs = append(defs, definition{name: idx.name, sql: buf.String()})
+	}
+	for _, idx := range table.spatialIndexes {
+		buf.Reset()
+		m.generateSpatialIndexDefinition(&buf, idx)
+		defs = append(defs, definition{name: idx.name, sql: buf.String()})
+	}
+	for _, idx := range table.multiValuedIndexes {
+		buf.Reset()
+		m.generateIndexDefinition(&buf, idx.index())
+		defs = append(defs, definition{name: idx.name, sql: buf.String()})
+	}
+	return defs
+}
+
+func (m *Maker) columnDefinition(col *column) string {
+	var buf strings.Builder
+	m.generateColumnDefinition(&buf, col)
+	return buf.String()
+}
+
+func (m *Maker) primaryKeyDefinition(pk *PrimaryKey) string {
+	if pk == nil || len(pk.columns) == 0 {
+		return ""
+	}
+	var buf strings.Builder
+	m.generatePrimaryKeyDefinition(&buf, pk)
+	return buf.String()
+}
+
+func (m *Maker) partitionsDefinition(p *Partitions) string {
+	if p == nil {
+		return ""
+	}
+	var buf strings.Builder
+	m.generatePartitions(&buf, p)
+	return strings.TrimPrefix(buf.String(), "\n")
+}
+
+func (m *Maker) foreignKeyDefinition(fk *ForeignKey) string {
+	var buf strings.Builder
+	m.generateForeignKeyDefinition(&buf, fk)
+	return buf.String()
+}
+
+func (m *Maker) checkDefinition(chk *CheckConstraint) string {
+	var buf strings.Builder
+	m.generateCheckDefinition(&buf, chk)
+	return buf.String()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,417 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type DiffUser1 struct {
+	ID   int32 `ddl:",auto"`
+	Name string
+	Age  int32
+}
+
+func (*DiffUser1) Table() string {
+	return "user"
+}
+
+func (*DiffUser1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffUser1) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_age", "age"),
+	}
+}
+
+type DiffUser2 struct {
+	ID    int32 `ddl:",auto"`
+	Name  string
+	Email string `ddl:",size=255"`
+	Age   int32  `ddl:",null"`
+}
+
+func (*DiffUser2) Table() string {
+	return "user"
+}
+
+func (*DiffUser2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffUser2) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_email", "email"),
+	}
+}
+
+type DiffEntry1 struct {
+	ID     int32 `ddl:",auto"`
+	UserID int32
+	Title  string
+}
+
+func (*DiffEntry1) Table() string {
+	return "entry"
+}
+
+func (*DiffEntry1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffEntry1) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_user_id", "user_id"),
+	}
+}
+
+func (*DiffEntry1) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_entry_user", []string{"user_id"}, "user", []string{"id"}),
+	}
+}
+
+type DiffEntry2 struct {
+	ID    int32 `ddl:",auto"`
+	Title string
+}
+
+func (*DiffEntry2) Table() string {
+	return "entry"
+}
+
+func (*DiffEntry2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type DiffComment struct {
+	ID      int32 `ddl:",auto"`
+	EntryID int32
+}
+
+func (*DiffComment) Table() string {
+	return "comment"
+}
+
+func (*DiffComment) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffComment) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_entry_id", "entry_id"),
+	}
+}
+
+func (*DiffComment) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_comment_entry", []string{"entry_id"}, "entry", []string{"id"}),
+	}
+}
+
+type DiffMember struct {
+	ID       int32  `ddl:",auto"`
+	FullName string `ddl:",renamed_from=name"`
+	Age      int32  `ddl:",null"`
+}
+
+func (*DiffMember) Table() string {
+	return "member"
+}
+
+func (*DiffMember) RenamedFrom() []string {
+	return []string{"user"}
+}
+
+func (*DiffMember) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffMember) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_age", "age"),
+	}
+}
+
+type DiffEntry3 struct {
+	ID       int32 `ddl:",auto"`
+	MemberID int32 `ddl:",renamed_from=user_id"`
+	Title    string
+}
+
+func (*DiffEntry3) Table() string {
+	return "entry"
+}
+
+func (*DiffEntry3) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffEntry3) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_user_id", "member_id"),
+	}
+}
+
+func (*DiffEntry3) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_entry_user", []string{"member_id"}, "member", []string{"id"}),
+	}
+}
+
+type DiffUser3 struct {
+	ID   int32 `ddl:",auto"`
+	Name string
+	Age  int32
+}
+
+func (*DiffUser3) Table() string {
+	return "user"
+}
+
+func (*DiffUser3) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffUser3) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_age", "age"),
+	}
+}
+
+func (*DiffUser3) Checks() []*CheckConstraint {
+	return []*CheckConstraint{
+		NewCheck("chk_age", "`age` >= 0"),
+	}
+}
+
+type DiffUser4 struct {
+	ID   int32 `ddl:",auto"`
+	Name string
+	Age  int32
+}
+
+func (*DiffUser4) Table() string {
+	return "user"
+}
+
+func (*DiffUser4) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffUser4) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_age", "age"),
+	}
+}
+
+func (*DiffUser4) TableOptions() *TableOptions {
+	return NewTableOptions().RowFormat("COMPRESSED").KeyBlockSize(8).Comment("users")
+}
+
+type DiffUser5 struct {
+	ID   int32 `ddl:",auto"`
+	Name string
+	Age  int32
+}
+
+func (*DiffUser5) Table() string {
+	return "user"
+}
+
+func (*DiffUser5) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*DiffUser5) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_age", "age"),
+	}
+}
+
+func (*DiffUser5) TableOptions() *TableOptions {
+	return NewTableOptions().Charset("latin1").Comment("members").AutoIncrement(100)
+}
+
+type DiffLog0 struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+}
+
+func (*DiffLog0) Table() string {
+	return "log"
+}
+
+func (*DiffLog0) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type DiffLog1 struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+}
+
+func (*DiffLog1) Table() string {
+	return "log"
+}
+
+func (*DiffLog1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "user_id")
+}
+
+type DiffLog2 struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+}
+
+func (*DiffLog2) Table() string {
+	return "log"
+}
+
+func (*DiffLog2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "user_id")
+}
+
+func (*DiffLog2) Partitions() *Partitions {
+	return NewHashPartitions("`user_id`").Count(4)
+}
+
+func testDiff(t *testing.T, oldStructs, newStructs []any, want string) {
+	t.Helper()
+
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(oldStructs...)
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine: "InnoDB",
+		},
+		AllowDestructive: []string{"user", "entry", "comment"},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(newStructs...)
+
+	var buf bytes.Buffer
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatalf("failed to generate diff: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateDiff(t *testing.T) {
+	// no changes
+	testDiff(t, []any{&DiffUser1{}}, []any{&DiffUser1{}}, "")
+
+	// create tables
+	testDiff(t, []any{}, []any{&DiffUser1{}, &DiffEntry1{}}, "CREATE TABLE `user` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `name` VARCHAR(191) NOT NULL,\n"+
+		"    `age` INTEGER NOT NULL,\n"+
+		"    INDEX `idx_age` (`age`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB;\n"+
+		"CREATE TABLE `entry` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `user_id` INTEGER NOT NULL,\n"+
+		"    `title` VARCHAR(191) NOT NULL,\n"+
+		"    INDEX `idx_user_id` (`user_id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB;\n"+
+		"ALTER TABLE `entry` ADD CONSTRAINT `fk_entry_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`);\n")
+
+	// add, modify and drop columns and indexes
+	testDiff(t, []any{&DiffUser1{}}, []any{&DiffUser2{}}, "ALTER TABLE `user` DROP INDEX `idx_age`;\n"+
+		"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NULL;\n"+
+		"ALTER TABLE `user` ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`;\n"+
+		"ALTER TABLE `user` ADD UNIQUE `uniq_email` (`email`);\n")
+
+	// the foreign key constraint is dropped before the column it depends on.
+	testDiff(t, []any{&DiffUser1{}, &DiffEntry1{}}, []any{&DiffUser1{}, &DiffEntry2{}}, "ALTER TABLE `entry` DROP FOREIGN KEY `fk_entry_user`;\n"+
+		"ALTER TABLE `entry` DROP INDEX `idx_user_id`;\n"+
+		"ALTER TABLE `entry` DROP COLUMN `user_id`;\n")
+
+	// the foreign key constraint is added after the table it depends on.
+	testDiff(t, []any{&DiffEntry2{}}, []any{&DiffComment{}, &DiffEntry2{}}, "CREATE TABLE `comment` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `entry_id` INTEGER NOT NULL,\n"+
+		"    INDEX `idx_entry_id` (`entry_id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB;\n"+
+		"ALTER TABLE `comment` ADD CONSTRAINT `fk_comment_entry` FOREIGN KEY (`entry_id`) REFERENCES `entry` (`id`);\n")
+
+	// drop tables
+	testDiff(t, []any{&DiffUser1{}, &DiffEntry1{}}, []any{}, "ALTER TABLE `entry` DROP FOREIGN KEY `fk_entry_user`;\n"+
+		"DROP TABLE `user`;\n"+
+		"DROP TABLE `entry`;\n")
+
+	// rename tables and columns
+	testDiff(t, []any{&DiffUser1{}, &DiffEntry1{}}, []any{&DiffMember{}, &DiffEntry3{}}, "RENAME TABLE `user` TO `member`;\n"+
+		"ALTER TABLE `member` RENAME COLUMN `name` TO `full_name`;\n"+
+		"ALTER TABLE `entry` RENAME COLUMN `user_id` TO `member_id`;\n"+
+		"ALTER TABLE `member` MODIFY COLUMN `age` INTEGER NULL;\n")
+
+	// add and drop check constraints
+	testDiff(t, []any{&DiffUser1{}}, []any{&DiffUser3{}}, "ALTER TABLE `user` ADD CONSTRAINT `chk_age` CHECK (`age` >= 0);\n")
+	testDiff(t, []any{&DiffUser3{}}, []any{&DiffUser1{}}, "ALTER TABLE `user` DROP CHECK `chk_age`;\n")
+
+	// change table options, the removed options are reset to the default values.
+	testDiff(t, []any{&DiffUser1{}}, []any{&DiffUser4{}}, "ALTER TABLE `user` ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 COMMENT='users';\n")
+	testDiff(t, []any{&DiffUser4{}}, []any{&DiffUser5{}}, "ALTER TABLE `user` ROW_FORMAT=DEFAULT KEY_BLOCK_SIZE=0 DEFAULT CHARACTER SET=latin1 COMMENT='members';\n")
+
+	// AUTO_INCREMENT is the initial value of the counter, it is not changed.
+	testDiff(t, []any{&DiffUser5{}}, []any{&DiffUser5{}}, "")
+
+	// the primary key with the AUTO_INCREMENT column is replaced in one statement.
+	testDiff(t, []any{&DiffLog0{}}, []any{&DiffLog1{}}, "ALTER TABLE `log` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`, `user_id`);\n")
+	testDiff(t, []any{&DiffLog1{}}, []any{&DiffLog0{}}, "ALTER TABLE `log` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`);\n")
+
+	// partition and remove partitioning
+	testDiff(t, []any{&DiffLog1{}}, []any{&DiffLog2{}}, "ALTER TABLE `log` PARTITION BY HASH (`user_id`) PARTITIONS 4;\n")
+	testDiff(t, []any{&DiffLog2{}}, []any{&DiffLog1{}}, "ALTER TABLE `log` REMOVE PARTITIONING;\n")
+	testDiff(t, []any{&DiffLog2{}}, []any{&DiffLog2{}}, "")
+
+	// the tables and the columns are already renamed.
+	testDiff(t, []any{&DiffMember{}}, []any{&DiffMember{}}, "")
+}
+
+func TestMaker_GenerateDiff_RenameError(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&DiffEntry2{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&DiffMember{})
+
+	var buf bytes.Buffer
+	err = m.GenerateDiff(&buf, schema)
+	if err == nil {
+		t.Fatal("want some error, but not")
+	}
+	want := `myddlmaker: table "member": renamed from unknown table: ["user"]`
+	if err.Error() != want {
+		t.Errorf("unexpected error: got %q, want %q", err.Error(), want)
+	}
+}
@@ -1,5 +1,10 @@
 package myddlmaker
 
+import (
+	"strconv"
+	"strings"
+)
+
 type indexes interface {
 	Indexes() []*Index
 }
@@ -12,6 +17,98 @@ type foreignKeys interface {
 	ForeignKeys() []*ForeignKey
 }
 
+// keyPart is a key part of an index.
+// https://dev.mysql.com/doc/refman/8.0/en/create-index.html
+type keyPart struct {
+	// column is the name of the column.
+	// It is empty if the key part is a functional key part.
+	column string
+
+	// length is the prefix length of the column.
+	length int
+
+	// expr is the expression of the functional key part.
+	expr string
+
+	// desc marks the key part descending.
+	desc bool
+
+	// The validator reports it, unless the whole string is the name of a column of the table.
+	// The validator reports it.
+	err error
+}
+
+// newKeyParts parses the key parts of NewIndex and NewUniqueIndex,
+// e.g. "name", "name(32)", "created_at DESC" and "(LOWER(email))".
+func newKeyParts(col []string) []*keyPart {
+	parts := make([]*keyPart, 0, len(col))
+	for _, c := range col {
+		parts = append(parts, parseKeyPart(c))
+	}
+	return parts
+}
+
+func parseKeyPart(s string) *keyPart {
+	p, err := newDDLParser(s)
+	if err != nil {
+		return &keyPart{column: s, err: err}
+	}
+	part, err := p.parseKeyPart()
+	if err != nil {
+		return &keyPart{column: s, err: err}
+	}
+	if tok := p.peek(0); tok.kind != tokenEOF {
+		return &keyPart{column: s, err: p.errorf(tok, "unexpected %q", tok.val)}
+	}
+	return part
+}
+
+// String returns the key part in the format that NewIndex accepts.
+func (part *keyPart) String() string {
+	var buf strings.Builder
+	if part.column != "" {
+		buf.WriteString(part.column)
+		if part.length > 0 {
+			buf.WriteString("(")
+			buf.WriteString(strconv.Itoa(part.length))
+			buf.WriteString(")")
+		}
+	} else {
+		buf.WriteString("(")
+		buf.WriteString(part.expr)
+		buf.WriteString(")")
+	}
+	if part.desc {
+		buf.WriteString(" DESC")
+	}
+	return buf.String()
+}
+
+// keyPartColumns returns the names of the columns in the key parts.
+// The columns in functional key parts are not included.
+func keyPartColumns(parts []*keyPart) []string {
+	columns := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.column != "" {
+			columns = append(columns, part.column)
+		}
+	}
+	return columns
+}
+
+// leadingColumns returns the leading columns of the key parts that index whole values.
+// The prefix key parts and the functional key parts can't be used by foreign keys.
+func leadingColumns(parts []*keyPart) []string {
+	columns := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.column == "" || part.length > 0 {
+			break
+		}
+		columns = append(columns, part.column)
+	}
+	return columns
+}
+
 // Index is an index of a table.
 // Implement the Indexes method to define the indexes.
 //
@@ -19,16 +116,28 @@ type foreignKeys interface {
 //	    return []*myddlmaker.Index{
 //	        // INDEX `idx_name` (`name`)
 //	        myddlmaker.NewIndex("idx_name", "name"),
+//
+//	        // INDEX `idx_name_created_at` (`name`(32), `created_at` DESC)
+//	        myddlmaker.NewIndex("idx_name_created_at", "name(32)", "created_at DESC"),
+//
+//	        // INDEX `idx_email` ((LOWER(`email`)))
+//	        myddlmaker.NewIndex("idx_email", "(LOWER(`email`))"),
 //	    }
 //	}
 type Index struct {
 	name      string
-	columns   []string
+	keyParts  []*keyPart
 	comment   string
 	invisible bool
+
+	// implicit marks the index that MySQL created for the foreign key of the same name.
+	// LoadSchema sets it.
+	implicit bool
 }
 
 // NewIndex returns a new index.
+// col is a key part, a column name with the optional prefix length and order, e.g. "name(32) DESC",
+// or an expression enclosed with parentheses, e.g. "(LOWER(`email`))".
 func NewIndex(name string, col ...string) *Index {
 	if name == "" {
 		panic("name is missing")
@@ -37,8 +146,8 @@ func NewIndex(name string, col ...string) *Index {
 		panic("col is missing")
 	}
 	return &Index{
-		name:    name,
-		columns: col,
+		name:     name,
+		keyParts: newKeyParts(col),
 	}
 }
 
@@ -67,12 +176,13 @@ func (idx *Index) Invisible() *Index {
 //	}
 type UniqueIndex struct {
 	name      string
-	columns   []string
+	keyParts  []*keyPart
 	comment   string
 	invisible bool
 }
 
 // NewUniqueIndex returns a new unique index.
+// col is a key part in the same format as NewIndex.
 func NewUniqueIndex(name string, col ...string) *UniqueIndex {
 	if name == "" {
 		panic("name is missing")
@@ -81,8 +191,8 @@ func NewUniqueIndex(name string, col ...string) *UniqueIndex {
 		panic("col is missing")
 	}
 	return &UniqueIndex{
-		name:    name,
-		columns: col,
+		name:     name,
+		keyParts: newKeyParts(col),
 	}
 }
 
@@ -191,6 +301,49 @@ func (fk *ForeignKey) OnDelete(opt ForeignKeyOption) *ForeignKey {
 	return &key
 }
 
+type checks interface {
+	Checks() []*CheckConstraint
+}
+
+// CheckConstraint is a CHECK constraint.
+// It is available on MySQL 8.0.16 or later.
+// https://dev.mysql.com/doc/refman/8.0/en/create-table-check-constraints.html
+// Implement the Checks method to define the CHECK constraints.
+//
+//	func (*User) Checks() []*myddlmaker.CheckConstraint {
+//		return []*myddlmaker.CheckConstraint{
+//			// CONSTRAINT `chk_age` CHECK (`age` >= 0)
+//			myddlmaker.NewCheck("chk_age", "`age` >= 0"),
+//		}
+//	}
+type CheckConstraint struct {
+	name        string
+	expr        string
+	notEnforced bool
+}
+
+// NewCheck returns a new CHECK constraint.
+// expr is an SQL expression that the rows must satisfy.
+func NewCheck(name, expr string) *CheckConstraint {
+	if name == "" {
+		panic("name is missing")
+	}
+	if expr == "" {
+		panic("expr is missing")
+	}
+	return &CheckConstraint{
+		name: name,
+		expr: expr,
+	}
+}
+
+// NotEnforced returns a copy of chk, but MySQL doesn't enforce it.
+func (chk *CheckConstraint) NotEnforced() *CheckConstraint {
+	tmp := *chk // shallow copy
+	tmp.notEnforced = true
+	return &tmp
+}
+
 type fullTextIndexes interface {
 	FullTextIndexes() []*FullTextIndex
 }
@@ -295,3 +448,254 @@ func (idx *SpatialIndex) Comment(comment string) *SpatialIndex {
 	tmp.comment = comment
 	return &tmp
 }
+
+type multiValuedIndexes interface {
+	MultiValuedIndexes() []*MultiValuedIndex
+}
+
+// MultiValuedIndex is a multi-valued index on a JSON array.
+// It is available on MySQL 8.0.17 or later.
+// https://dev.mysql.com/doc/refman/8.0/en/create-index.html#create-index-multi-valued
+// Implement the MultiValuedIndexes method to define the multi-valued indexes.
+//
+//	func (*User) MultiValuedIndexes() []*myddlmaker.MultiValuedIndex {
+//		return []*myddlmaker.MultiValuedIndex{
+//			// INDEX `idx_tags` ((CAST(`tags`->'$' AS UNSIGNED ARRAY)))
+//			myddlmaker.NewMultiValuedIndex("idx_tags", "tags", "UNSIGNED"),
+//		}
+//	}
+type MultiValuedIndex struct {
+	name   string
+	column string
+
+	// path is the JSON path to the array.
+	path string
+
+	// typ is the type of the elements, e.g. UNSIGNED, SIGNED and CHAR(64).
+	typ string
+
+	invisible bool
+	comment   string
+}
+
+// NewMultiValuedIndex returns a new multi-valued index on the JSON array in column.
+// typ is the type of the elements that the CAST function accepts,
+// e.g. "SIGNED", "UNSIGNED", "CHAR(64)", "DATE" and "DECIMAL(10, 2)".
+func NewMultiValuedIndex(name string, column string, typ string) *MultiValuedIndex {
+	if name == "" {
+		panic("name is missing")
+	}
+	if column == "" {
+		panic("column is missing")
+	}
+	if typ == "" {
+		panic("typ is missing")
+	}
+	return &MultiValuedIndex{
+		name:   name,
+		column: column,
+		path:   "$",
+		typ:    typ,
+	}
+}
+
+// Path returns a copy of idx with the JSON path to the array.
+// The default path is "$", the array is the whole value of the column.
+func (idx *MultiValuedIndex) Path(path string) *MultiValuedIndex {
+	tmp := *idx // shallow copy
+	tmp.path = path
+	return &tmp
+}
+
+// Invisible returns a copy of idx, but it is invisible from MySQL planner.
+func (idx *MultiValuedIndex) Invisible() *MultiValuedIndex {
+	tmp := *idx // shallow copy
+	tmp.invisible = true
+	return &tmp
+}
+
+// Comment returns a copy of idx with the comment.
+func (idx *MultiValuedIndex) Comment(comment string) *MultiValuedIndex {
+	tmp := *idx // shallow copy
+	tmp.comment = comment
+	return &tmp
+}
+
+// expr returns the expression of the functional key part.
+func (idx *MultiValuedIndex) expr() string {
+	return "CAST(" + quote(idx.column) + "->" + stringQuote(idx.path) + " AS " + idx.typ + " ARRAY)"
+}
+
+// index returns the functional index that is equivalent to idx.
+func (idx *MultiValuedIndex) index() *Index {
+	return &Index{
+		name:      idx.name,
+		keyParts:  []*keyPart{{expr: idx.expr()}},
+		comment:   idx.comment,
+		invisible: idx.invisible,
+	}
+}
+
+type partitions interface {
+	Partitions() *Partitions
+}
+
+// Partitions is a partitioning of a table.
+// https://dev.mysql.com/doc/refman/8.0/en/partitioning-types.html
+// Implement the Partitions method to partition the table.
+//
+//	func (*Log) Partitions() *myddlmaker.Partitions {
+//		// PARTITION BY RANGE COLUMNS(`created_at`) (
+//		//     PARTITION `p2022` VALUES LESS THAN ('2023-01-01 00:00:00'),
+//		//     PARTITION `pmax` VALUES LESS THAN (MAXVALUE)
+//		// )
+//		return myddlmaker.NewRangeColumnsPartitions("created_at").Add(
+//			myddlmaker.NewPartition("p2022").LessThan("'2023-01-01 00:00:00'"),
+//			myddlmaker.NewPartition("pmax").LessThan("MAXVALUE"),
+//		)
+//	}
+type Partitions struct {
+	// typ is RANGE, LIST, HASH or KEY.
+	typ string
+
+	// expr is the partitioning expression of RANGE, LIST and HASH.
+	expr string
+
+	// columns is the partitioning columns of RANGE COLUMNS, LIST COLUMNS and KEY.
+	columns []string
+
+	linear      bool
+	count       int
+	definitions []*Partition
+}
+
+// NewRangePartitions returns a new partitioning by RANGE (expr).
+func NewRangePartitions(expr string) *Partitions {
+	if expr == "" {
+		panic("expr is missing")
+	}
+	return &Partitions{
+		typ:  "RANGE",
+		expr: expr,
+	}
+}
+
+// NewRangeColumnsPartitions returns a new partitioning by RANGE COLUMNS (col, ...).
+func NewRangeColumnsPartitions(col ...string) *Partitions {
+	if len(col) == 0 {
+		panic("col is missing")
+	}
+	return &Partitions{
+		typ:     "RANGE",
+		columns: col,
+	}
+}
+
+// NewListPartitions returns a new partitioning by LIST (expr).
+func NewListPartitions(expr string) *Partitions {
+	if expr == "" {
+		panic("expr is missing")
+	}
+	return &Partitions{
+		typ:  "LIST",
+		expr: expr,
+	}
+}
+
+// NewListColumnsPartitions returns a new partitioning by LIST COLUMNS (col, ...).
+func NewListColumnsPartitions(col ...string) *Partitions {
+	if len(col) == 0 {
+		panic("col is missing")
+	}
+	return &Partitions{
+		typ:     "LIST",
+		columns: col,
+	}
+}
+
+// NewHashPartitions returns a new partitioning by HASH (expr).
+func NewHashPartitions(expr string) *Partitions {
+	if expr == "" {
+		panic("expr is missing")
+	}
+	return &Partitions{
+		typ:  "HASH",
+		expr: expr,
+	}
+}
+
+// NewKeyPartitions returns a new partitioning by KEY (col, ...).
+// If col is empty, the primary key is used.
+func NewKeyPartitions(col ...string) *Partitions {
+	return &Partitions{
+		typ:     "KEY",
+		columns: col,
+	}
+}
+
+// Linear returns a copy of p with linear hashing.
+// It is available for HASH and KEY partitioning.
+func (p *Partitions) Linear() *Partitions {
+	tmp := *p // shallow copy
+	tmp.linear = true
+	return &tmp
+}
+
+// Count returns a copy of p with the number of partitions.
+// It is available for HASH and KEY partitioning.
+func (p *Partitions) Count(n int) *Partitions {
+	tmp := *p // shallow copy
+	tmp.count = n
+	return &tmp
+}
+
+// Add returns a copy of p with the partition definitions.
+// They are required for RANGE and LIST partitioning.
+func (p *Partitions) Add(def ...*Partition) *Partitions {
+	tmp := *p // shallow copy
+	tmp.definitions = append(append([]*Partition(nil), p.definitions...), def...)
+	return &tmp
+}
+
+// Partition is a definition of a partition.
+type Partition struct {
+	name    string
+	op      string
+	values  []string
+	comment string
+}
+
+// NewPartition returns a new partition definition.
+func NewPartition(name string) *Partition {
+	if name == "" {
+		panic("name is missing")
+	}
+	return &Partition{
+		name: name,
+	}
+}
+
+// LessThan returns a copy of p with VALUES LESS THAN (values, ...).
+// The values are SQL expressions, e.g. "100", "'2023-01-01'" and "MAXVALUE".
+func (p *Partition) LessThan(values ...string) *Partition {
+	tmp := *p // shallow copy
+	tmp.op = "LESS THAN"
+	tmp.values = values
+	return &tmp
+}
+
+// In returns a copy of p with VALUES IN (values, ...).
+// The values are SQL expressions.
+func (p *Partition) In(values ...string) *Partition {
+	tmp := *p // shallow copy
+	tmp.op = "IN"
+	tmp.values = values
+	return &tmp
+}
+
+// Comment returns a copy of p with the comment.
+func (p *Partition) Comment(comment string) *Partition {
+	tmp := *p // shallow copy
+	tmp.comment = comment
+	return &tmp
+}
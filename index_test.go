@@ -0,0 +1,62 @@
+package myddlmaker
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseKeyPart(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *keyPart
+	}{
+		{in: "name", want: &keyPart{column: "name"}},
+		{in: "`name`", want: &keyPart{column: "name"}},
+		{in: "name(32)", want: &keyPart{column: "name", length: 32}},
+		{in: "created_at DESC", want: &keyPart{column: "created_at", desc: true}},
+		{in: "created_at ASC", want: &keyPart{column: "created_at"}},
+		{in: "name(32) desc", want: &keyPart{column: "name", length: 32, desc: true}},
+		{in: "(LOWER(`email`))", want: &keyPart{expr: "LOWER(`email`)"}},
+		{in: "(LOWER(`email`)) DESC", want: &keyPart{expr: "LOWER(`email`)", desc: true}},
+	}
+
+	for _, tt := range tests {
+		got := parseKeyPart(tt.in)
+		if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(keyPart{})); diff != "" {
+			t.Errorf("%q: key part is not match: (-want/+got)\n%s", tt.in, diff)
+		}
+	}
+}
+
+func TestParseKeyPart_Error(t *testing.T) {
+	tests := []string{
+		"name(foo)",
+		"first name",
+		"(LOWER(`email`)",
+	}
+
+	for _, tt := range tests {
+		got := parseKeyPart(tt)
+		if got.err == nil {
+			t.Errorf("%q: want error, but got nil", tt)
+		}
+	}
+}
+
+func TestKeyPart_String(t *testing.T) {
+	tests := []string{
+		"name",
+		"name(32)",
+		"created_at DESC",
+		"(LOWER(`email`))",
+		"(LOWER(`email`)) DESC",
+	}
+
+	for _, tt := range tests {
+		got := parseKeyPart(tt).String()
+		if got != tt {
+			t.Errorf("got %q, want %q", got, tt)
+		}
+	}
+}
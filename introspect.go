@@ -0,0 +1,680 @@
+package myddlmaker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadSchema reads the definitions of the tables in the database through information_schema,
+// and returns the schema that they define.
+// information_schema of MariaDB lacks some columns that MySQL has, e.g. SRS_ID and IS_VISIBLE,
+// so the tables of MariaDB are read by SHOW CREATE TABLE instead.
+// The sequences of MariaDB and TiDB are read by SHOW CREATE SEQUENCE.
+// If database is empty, the current database of db is used.
+func LoadSchema(ctx context.Context, db *sql.DB, database string) (*Schema, error) {
+	if database == "" {
+		var name sql.NullString
+		if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&name); err != nil {
+			return nil, fmt.Errorf("myddlmaker: failed to get the current database: %w", err)
+		}
+		if !name.Valid {
+			return nil, fmt.Errorf("myddlmaker: no database selected")
+		}
+		database = name.String
+	}
+
+	l := &schemaLoader{
+		db:       db,
+		database: database,
+		tableMap: map[string]*table{},
+	}
+	mariaDB, err := l.isMariaDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.loadTables(ctx); err != nil {
+		return nil, err
+	}
+	if mariaDB {
+		if err := l.loadCreateTables(ctx); err != nil {
+			return nil, err
+		}
+		l.markImplicitIndexes()
+		if err := l.loadSequences(ctx); err != nil {
+			return nil, err
+		}
+		return &Schema{
+			tables:    l.tables,
+			sequences: l.sequences,
+		}, nil
+	}
+	if err := l.loadColumns(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.loadIndexes(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.loadForeignKeys(ctx); err != nil {
+		return nil, err
+	}
+	l.markImplicitIndexes()
+	if err := l.loadChecks(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.loadPartitions(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.loadSequences(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, table := range l.tables {
+		if table.primaryKey == nil {
+			return nil, fmt.Errorf("myddlmaker: table %q: primary key is missing", table.name)
+		}
+	}
+	return &Schema{
+		tables:    l.tables,
+		sequences: l.sequences,
+	}, nil
+}
+
+type schemaLoader struct {
+	db        *sql.DB
+	database  string
+	tables    []*table
+	sequences []*Sequence
+
+	// key: table name
+	tableMap map[string]*table
+}
+
+// lookup returns the table named name.
+func (l *schemaLoader) lookup(name string) (*table, error) {
+	table, ok := l.tableMap[name]
+	if !ok {
+		return nil, fmt.Errorf("myddlmaker: table %q not found", name)
+	}
+	return table, nil
+}
+
+// isMariaDB reports whether the server is MariaDB, e.g. "10.11.2-MariaDB".
+func (l *schemaLoader) isMariaDB(ctx context.Context) (bool, error) {
+	var version string
+	if err := l.db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return false, fmt.Errorf("myddlmaker: failed to get the version of the server: %w", err)
+	}
+	return strings.Contains(version, "MariaDB"), nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-tables-table.html
+const queryTables = "SELECT t.`TABLE_NAME`, t.`ENGINE`, t.`TABLE_COLLATION`, c.`CHARACTER_SET_NAME`, t.`CREATE_OPTIONS`, t.`TABLE_COMMENT` " +
+	"FROM `information_schema`.`TABLES` t " +
+	"LEFT JOIN `information_schema`.`COLLATION_CHARACTER_SET_APPLICABILITY` c ON c.`COLLATION_NAME` = t.`TABLE_COLLATION` " +
+	"WHERE t.`TABLE_SCHEMA` = ? AND t.`TABLE_TYPE` = 'BASE TABLE' " +
+	"ORDER BY t.`TABLE_NAME`"
+
+func (l *schemaLoader) loadTables(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryTables, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var engine, collate, charset, options, comment sql.NullString
+		if err := rows.Scan(&name, &engine, &collate, &charset, &options, &comment); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan tables: %w", err)
+		}
+		table := &table{
+			name:    name,
+			rawName: snakeToCamel(name),
+			engine:  engine.String,
+			charset: charset.String,
+			collate: collate.String,
+			comment: comment.String,
+		}
+		if err := parseCreateOptions(table, options.String); err != nil {
+			return err
+		}
+		l.tables = append(l.tables, table)
+		l.tableMap[name] = table
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query tables: %w", err)
+	}
+	return nil
+}
+
+// loadCreateTables replaces the tables with the definitions that SHOW CREATE TABLE returns.
+// MariaDB returns COLUMN_DEFAULT of information_schema.COLUMNS already quoted,
+// and doesn't have some columns that the other loaders query, so the definitions are parsed instead.
+func (l *schemaLoader) loadCreateTables(ctx context.Context) error {
+	for i, table := range l.tables {
+		var name, create string
+		query := "SHOW CREATE TABLE " + quote(l.database) + "." + quote(table.name)
+		if err := l.db.QueryRowContext(ctx, query).Scan(&name, &create); err != nil {
+			return fmt.Errorf("myddlmaker: failed to show table %q: %w", table.name, err)
+		}
+		p, err := newDDLParser(create)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to parse table %q: %w", table.name, err)
+		}
+		tbl, err := p.parseCreateTable()
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to parse table %q: %w", table.name, err)
+		}
+		for _, col := range tbl.columns {
+			// MariaDB writes current_timestamp() in lower case.
+			col.def = upperCurrentTimestamp(col.def)
+			col.onUpdate = upperCurrentTimestamp(col.onUpdate)
+		}
+		l.tables[i] = tbl
+		l.tableMap[tbl.name] = tbl
+	}
+	return nil
+}
+
+// upperCurrentTimestamp converts current_timestamp() into CURRENT_TIMESTAMP, as MySQL and the structs write.
+func upperCurrentTimestamp(expr string) string {
+	upper := strings.ToUpper(expr)
+	if !strings.HasPrefix(upper, "CURRENT_TIMESTAMP") {
+		return expr
+	}
+	return strings.TrimSuffix(upper, "()")
+}
+
+// parseCreateOptions parses CREATE_OPTIONS of information_schema.TABLES,
+// e.g. "row_format=COMPRESSED KEY_BLOCK_SIZE=8 stats_persistent=1".
+func parseCreateOptions(table *table, options string) error {
+	for _, opt := range strings.Fields(options) {
+		name, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			// e.g. partitioned
+			continue
+		}
+		switch strings.ToUpper(name) {
+		case "ROW_FORMAT":
+			table.rowFormat = strings.ToUpper(value)
+		case "KEY_BLOCK_SIZE":
+			size, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("myddlmaker: table %q: invalid KEY_BLOCK_SIZE: %q", table.name, value)
+			}
+			table.keyBlockSize = size
+		case "STATS_PERSISTENT":
+			table.statsPersistent = strings.ToUpper(value)
+		}
+	}
+	return nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-columns-table.html
+const queryColumns = "SELECT `TABLE_NAME`, `COLUMN_NAME`, `COLUMN_TYPE`, `IS_NULLABLE`, `COLUMN_DEFAULT`, `EXTRA`, " +
+	"`CHARACTER_SET_NAME`, `COLLATION_NAME`, `COLUMN_COMMENT`, `SRS_ID`, `GENERATION_EXPRESSION` " +
+	"FROM `information_schema`.`COLUMNS` " +
+	"WHERE `TABLE_SCHEMA` = ? " +
+	"ORDER BY `TABLE_NAME`, `ORDINAL_POSITION`"
+
+func (l *schemaLoader) loadColumns(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryColumns, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, name, columnType, nullable, extra, comment string
+		var def, charset, collate, generated sql.NullString
+		var srid sql.NullInt64
+		if err := rows.Scan(&tableName, &name, &columnType, &nullable, &def, &extra, &charset, &collate, &comment, &srid, &generated); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan columns: %w", err)
+		}
+		table, ok := l.tableMap[tableName]
+		if !ok {
+			// it may be a view.
+			continue
+		}
+
+		col := &column{
+			name:    name,
+			rawName: snakeToCamel(name),
+			null:    nullable == "YES",
+			comment: comment,
+			srid:    int(srid.Int64),
+		}
+		if err := parseColumnType(col, columnType); err != nil {
+			return fmt.Errorf("myddlmaker: table %q, column %q: %w", tableName, name, err)
+		}
+
+		// information_schema reports the character set and the collation of all string columns.
+		// omit them if they are same as the table's.
+		if charset.String != table.charset {
+			col.charset = charset.String
+		}
+		if collate.String != table.collate {
+			col.collate = collate.String
+		}
+
+		extra = strings.ToUpper(extra)
+		for _, attr := range strings.Fields(extra) {
+			switch attr {
+			case "AUTO_INCREMENT":
+				col.autoIncr = true
+			case "INVISIBLE":
+				col.invisible = true
+			case "STORED":
+				// STORED GENERATED
+				col.stored = true
+			}
+		}
+		if strings.Contains(extra, "GENERATED") && !strings.Contains(extra, "DEFAULT_GENERATED") {
+			col.generated = generated.String
+		}
+		if _, onUpdate, ok := strings.Cut(extra, "ON UPDATE "); ok {
+			// e.g. DEFAULT_GENERATED on update CURRENT_TIMESTAMP(6)
+			if f := strings.Fields(onUpdate); len(f) > 0 {
+				col.onUpdate = f[0]
+			}
+		}
+
+		if def.Valid {
+			switch {
+			case strings.Contains(extra, "DEFAULT_GENERATED"):
+				// the default value is an expression.
+				if strings.HasPrefix(strings.ToUpper(def.String), "CURRENT_TIMESTAMP") {
+					col.def = def.String
+				} else {
+					col.def = "(" + def.String + ")"
+				}
+			case isNumericType(col.typ):
+				col.def = def.String
+			default:
+				col.def = stringQuote(def.String)
+			}
+		}
+
+		table.columns = append(table.columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query columns: %w", err)
+	}
+	return nil
+}
+
+// parseColumnType parses COLUMN_TYPE of information_schema.COLUMNS, e.g. "bigint unsigned", "varchar(191)".
+func parseColumnType(col *column, columnType string) error {
+	p, err := newDDLParser(columnType)
+	if err != nil {
+		return err
+	}
+	if err := p.parseDataType(col); err != nil {
+		return err
+	}
+	for p.peek(0).kind != tokenEOF {
+		switch {
+		case p.acceptKeyword("UNSIGNED"):
+			col.unsigned = true
+		case p.acceptKeyword("SIGNED"), p.acceptKeyword("ZEROFILL"):
+		default:
+			return p.errorf(p.peek(0), "unknown column type: %q", columnType)
+		}
+	}
+	return nil
+}
+
+func isNumericType(typ string) bool {
+	name, _, _ := strings.Cut(typ, "(")
+	if integerTypes[name] {
+		return true
+	}
+	switch name {
+	case "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL":
+		return true
+	}
+	return false
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-statistics-table.html
+const queryIndexes = "SELECT `TABLE_NAME`, `INDEX_NAME`, `NON_UNIQUE`, `COLUMN_NAME`, `INDEX_TYPE`, `INDEX_COMMENT`, `IS_VISIBLE`, " +
+	"`SUB_PART`, `COLLATION`, `EXPRESSION` " +
+	"FROM `information_schema`.`STATISTICS` " +
+	"WHERE `TABLE_SCHEMA` = ? " +
+	"ORDER BY `TABLE_NAME`, `INDEX_NAME`, `SEQ_IN_INDEX`"
+
+func (l *schemaLoader) loadIndexes(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryIndexes, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	type index struct {
+		table     *table
+		name      string
+		unique    bool
+		typ       string
+		comment   string
+		invisible bool
+		keyParts  []*keyPart
+	}
+	var indexes []*index
+	for rows.Next() {
+		var tableName, name, typ, comment, visible string
+		var nonUnique int
+		var columnName, collation, expr sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&tableName, &name, &nonUnique, &columnName, &typ, &comment, &visible, &subPart, &collation, &expr); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan indexes: %w", err)
+		}
+		table, ok := l.tableMap[tableName]
+		if !ok {
+			continue
+		}
+
+		if len(indexes) == 0 || indexes[len(indexes)-1].table != table || indexes[len(indexes)-1].name != name {
+			indexes = append(indexes, &index{
+				table:     table,
+				name:      name,
+				unique:    nonUnique == 0,
+				typ:       typ,
+				comment:   comment,
+				invisible: visible == "NO",
+			})
+		}
+		idx := indexes[len(indexes)-1]
+		idx.keyParts = append(idx.keyParts, &keyPart{
+			// COLUMN_NAME is NULL for functional key parts.
+			column: columnName.String,
+			length: int(subPart.Int64),
+			expr:   expr.String,
+			desc:   collation.String == "D",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query indexes: %w", err)
+	}
+
+	for _, idx := range indexes {
+		table := idx.table
+		columns := keyPartColumns(idx.keyParts)
+		if idx.name == "PRIMARY" || idx.typ == "FULLTEXT" || idx.typ == "SPATIAL" {
+			// they can't have functional key parts.
+			// SPATIAL indexes report SUB_PART of 32, but it is ignored.
+			if len(columns) != len(idx.keyParts) {
+				return fmt.Errorf("myddlmaker: table %q, index %q: functional key parts are not supported", table.name, idx.name)
+			}
+		}
+		switch {
+		case idx.name == "PRIMARY":
+			table.primaryKey = NewPrimaryKey(columns...)
+		case idx.typ == "FULLTEXT":
+			if len(columns) != 1 {
+				return fmt.Errorf("myddlmaker: table %q, index %q: FULLTEXT indexes with multiple columns are not supported", table.name, idx.name)
+			}
+			i := NewFullTextIndex(idx.name, columns[0])
+			i.comment = idx.comment
+			i.invisible = idx.invisible
+			table.fullTextIndexes = append(table.fullTextIndexes, i)
+		case idx.typ == "SPATIAL":
+			if len(columns) != 1 {
+				return fmt.Errorf("myddlmaker: table %q, index %q: SPATIAL indexes with multiple columns are not supported", table.name, idx.name)
+			}
+			i := NewSpatialIndex(idx.name, columns[0])
+			i.comment = idx.comment
+			i.invisible = idx.invisible
+			table.spatialIndexes = append(table.spatialIndexes, i)
+		case idx.unique:
+			i := &UniqueIndex{name: idx.name, keyParts: idx.keyParts}
+			i.comment = idx.comment
+			i.invisible = idx.invisible
+			table.uniqueIndexes = append(table.uniqueIndexes, i)
+		default:
+			i := &Index{name: idx.name, keyParts: idx.keyParts}
+			i.comment = idx.comment
+			i.invisible = idx.invisible
+			table.indexes = append(table.indexes, i)
+		}
+	}
+	return nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-key-column-usage-table.html
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-referential-constraints-table.html
+const queryForeignKeys = "SELECT k.`TABLE_NAME`, k.`CONSTRAINT_NAME`, k.`COLUMN_NAME`, k.`REFERENCED_TABLE_NAME`, k.`REFERENCED_COLUMN_NAME`, " +
+	"r.`UPDATE_RULE`, r.`DELETE_RULE` " +
+	"FROM `information_schema`.`KEY_COLUMN_USAGE` k " +
+	"INNER JOIN `information_schema`.`REFERENTIAL_CONSTRAINTS` r ON r.`CONSTRAINT_SCHEMA` = k.`CONSTRAINT_SCHEMA` AND r.`CONSTRAINT_NAME` = k.`CONSTRAINT_NAME` " +
+	"WHERE k.`TABLE_SCHEMA` = ? AND k.`REFERENCED_TABLE_NAME` IS NOT NULL " +
+	"ORDER BY k.`TABLE_NAME`, k.`CONSTRAINT_NAME`, k.`ORDINAL_POSITION`"
+
+func (l *schemaLoader) loadForeignKeys(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryForeignKeys, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var last *ForeignKey
+	var lastTable *table
+	for rows.Next() {
+		var tableName, name, columnName, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&tableName, &name, &columnName, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan foreign keys: %w", err)
+		}
+		table, err := l.lookup(tableName)
+		if err != nil {
+			return err
+		}
+
+		if last == nil || lastTable != table || last.name != name {
+			last = &ForeignKey{
+				name:     name,
+				table:    refTable,
+				onUpdate: foreignKeyRule(onUpdate),
+				onDelete: foreignKeyRule(onDelete),
+			}
+			lastTable = table
+			table.foreignKeys = append(table.foreignKeys, last)
+		}
+		last.columns = append(last.columns, columnName)
+		last.references = append(last.references, refColumn)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query foreign keys: %w", err)
+	}
+	return nil
+}
+
+// markImplicitIndexes marks the indexes that MySQL created for the foreign keys.
+// MySQL names them after the foreign key constraints if no index can be used for them.
+// https://dev.mysql.com/doc/refman/8.0/en/create-table-foreign-keys.html
+func (l *schemaLoader) markImplicitIndexes() {
+	for _, table := range l.tables {
+		for _, fk := range table.foreignKeys {
+			for _, idx := range table.indexes {
+				if idx.name == fk.name && equalStrings(leadingColumns(idx.keyParts), fk.columns) {
+					idx.implicit = true
+				}
+			}
+		}
+	}
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-table-constraints-table.html
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-check-constraints-table.html
+const queryChecks = "SELECT t.`TABLE_NAME`, t.`CONSTRAINT_NAME`, c.`CHECK_CLAUSE`, t.`ENFORCED` " +
+	"FROM `information_schema`.`TABLE_CONSTRAINTS` t " +
+	"INNER JOIN `information_schema`.`CHECK_CONSTRAINTS` c ON c.`CONSTRAINT_SCHEMA` = t.`CONSTRAINT_SCHEMA` AND c.`CONSTRAINT_NAME` = t.`CONSTRAINT_NAME` " +
+	"WHERE t.`TABLE_SCHEMA` = ? AND t.`CONSTRAINT_TYPE` = 'CHECK' " +
+	"ORDER BY t.`TABLE_NAME`, t.`CONSTRAINT_NAME`"
+
+func (l *schemaLoader) loadChecks(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryChecks, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, name, clause, enforced string
+		if err := rows.Scan(&tableName, &name, &clause, &enforced); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan check constraints: %w", err)
+		}
+		table, err := l.lookup(tableName)
+		if err != nil {
+			return err
+		}
+
+		// CHECK_CLAUSE is enclosed with parentheses, e.g. (`id` > 0).
+		chk := &CheckConstraint{
+			name:        name,
+			expr:        trimParens(clause),
+			notEnforced: enforced == "NO",
+		}
+		table.checks = append(table.checks, chk)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query check constraints: %w", err)
+	}
+	return nil
+}
+
+// https://dev.mysql.com/doc/refman/8.0/en/information-schema-partitions-table.html
+const queryPartitions = "SELECT `TABLE_NAME`, `PARTITION_NAME`, `SUBPARTITION_NAME`, `PARTITION_METHOD`, `PARTITION_EXPRESSION`, " +
+	"`PARTITION_DESCRIPTION`, `PARTITION_COMMENT` " +
+	"FROM `information_schema`.`PARTITIONS` " +
+	"WHERE `TABLE_SCHEMA` = ? AND `PARTITION_NAME` IS NOT NULL " +
+	"ORDER BY `TABLE_NAME`, `PARTITION_ORDINAL_POSITION`, `SUBPARTITION_ORDINAL_POSITION`"
+
+func (l *schemaLoader) loadPartitions(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, queryPartitions, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, name, method, comment string
+		var subpartition, expr, description sql.NullString
+		if err := rows.Scan(&tableName, &name, &subpartition, &method, &expr, &description, &comment); err != nil {
+			return fmt.Errorf("myddlmaker: failed to scan partitions: %w", err)
+		}
+		table, err := l.lookup(tableName)
+		if err != nil {
+			return err
+		}
+		if subpartition.Valid {
+			return fmt.Errorf("myddlmaker: table %q: subpartitioning is not supported", tableName)
+		}
+
+		p := table.partitions
+		if p == nil {
+			// PARTITION_METHOD is RANGE, RANGE COLUMNS, LIST, LIST COLUMNS, HASH, LINEAR HASH, KEY or LINEAR KEY.
+			p = &Partitions{}
+			typ := method
+			if strings.HasPrefix(typ, "LINEAR ") {
+				p.linear = true
+				typ = strings.TrimPrefix(typ, "LINEAR ")
+			}
+			if strings.HasSuffix(typ, " COLUMNS") || typ == "KEY" {
+				// PARTITION_EXPRESSION is the list of the columns, e.g. `a`,`b`.
+				columns, err := parseColumnList(expr.String)
+				if err != nil {
+					return fmt.Errorf("myddlmaker: table %q: failed to parse the partitioning columns: %w", tableName, err)
+				}
+				p.columns = columns
+				typ = strings.TrimSuffix(typ, " COLUMNS")
+			} else {
+				p.expr = expr.String
+			}
+			p.typ = typ
+			table.partitions = p
+		}
+
+		if p.typ == "HASH" || p.typ == "KEY" {
+			// the partitions of HASH and KEY partitioning are named p0, p1, ... by default.
+			p.count++
+			continue
+		}
+		def := &Partition{
+			name:    name,
+			comment: comment,
+		}
+		if p.typ == "RANGE" {
+			def.op = "LESS THAN"
+		} else {
+			def.op = "IN"
+		}
+		values, err := parseExpressions(description.String)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: table %q, partition %q: failed to parse the values: %w", tableName, name, err)
+		}
+		def.values = values
+		p.definitions = append(p.definitions, def)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query partitions: %w", err)
+	}
+	return nil
+}
+
+// MariaDB and TiDB list the sequences in information_schema.TABLES, but MySQL doesn't have sequences.
+// https://mariadb.com/kb/en/sequence-overview/
+const querySequences = "SELECT `TABLE_NAME` " +
+	"FROM `information_schema`.`TABLES` " +
+	"WHERE `TABLE_SCHEMA` = ? AND `TABLE_TYPE` = 'SEQUENCE' " +
+	"ORDER BY `TABLE_NAME`"
+
+func (l *schemaLoader) loadSequences(ctx context.Context) error {
+	rows, err := l.db.QueryContext(ctx, querySequences, l.database)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to query sequences: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("myddlmaker: failed to scan sequences: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("myddlmaker: failed to query sequences: %w", err)
+	}
+
+	// information_schema doesn't have the options of the sequences in MariaDB,
+	// so they are parsed from SHOW CREATE SEQUENCE.
+	for _, name := range names {
+		var seqName, create string
+		query := "SHOW CREATE SEQUENCE " + quote(l.database) + "." + quote(name)
+		if err := l.db.QueryRowContext(ctx, query).Scan(&seqName, &create); err != nil {
+			return fmt.Errorf("myddlmaker: failed to show sequence %q: %w", name, err)
+		}
+		p, err := newDDLParser(create)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to parse sequence %q: %w", name, err)
+		}
+		seq, err := p.parseCreateSequence()
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to parse sequence %q: %w", name, err)
+		}
+		l.sequences = append(l.sequences, seq)
+	}
+	return nil
+}
+
+// foreignKeyRule converts UPDATE_RULE and DELETE_RULE into ForeignKeyOption.
+func foreignKeyRule(rule string) ForeignKeyOption {
+	if rule == "NO ACTION" {
+		// NO ACTION is the default action.
+		return ""
+	}
+	return ForeignKeyOption(rule)
+}
@@ -0,0 +1,559 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// fakeResult is a canned result of queries that contain the query.
+type fakeResult struct {
+	query   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeConnector is a driver.Connector that returns canned results.
+type fakeConnector struct {
+	results []fakeResult
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{results: c.results}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakeConn struct {
+	results []fakeResult
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for _, r := range c.results {
+		if strings.Contains(query, r.query) {
+			return &fakeRows{columns: r.columns, rows: r.rows}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestLoadSchema(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{
+		results: []fakeResult{
+			{
+				query:   "SELECT DATABASE()",
+				columns: []string{"DATABASE()"},
+				rows:    [][]driver.Value{{"test"}},
+			},
+			{
+				query:   "SELECT VERSION()",
+				columns: []string{"VERSION()"},
+				rows:    [][]driver.Value{{"8.0.31"}},
+			},
+			{
+				query:   "`TABLE_TYPE` = 'SEQUENCE'",
+				columns: []string{"TABLE_NAME"},
+				rows:    [][]driver.Value{{"seq_order"}},
+			},
+			{
+				query:   "SHOW CREATE SEQUENCE `test`.`seq_order`",
+				columns: []string{"Table", "Create Table"},
+				rows: [][]driver.Value{
+					{"seq_order", "CREATE SEQUENCE `seq_order` start with 1000 minvalue 1000 maxvalue 9223372036854775806 increment by 10 cache 20 nocycle ENGINE=InnoDB"},
+				},
+			},
+			{
+				query:   "`information_schema`.`TABLES`",
+				columns: []string{"TABLE_NAME", "ENGINE", "TABLE_COLLATION", "CHARACTER_SET_NAME", "CREATE_OPTIONS", "TABLE_COMMENT"},
+				rows: [][]driver.Value{
+					{"entry", "InnoDB", "utf8mb4_bin", "utf8mb4", "row_format=COMPRESSED KEY_BLOCK_SIZE=8", "entries"},
+					{"user", "InnoDB", "utf8mb4_bin", "utf8mb4", "", ""},
+				},
+			},
+			{
+				query: "`information_schema`.`COLUMNS`",
+				columns: []string{
+					"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA",
+					"CHARACTER_SET_NAME", "COLLATION_NAME", "COLUMN_COMMENT", "SRS_ID", "GENERATION_EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					{"entry", "id", "bigint unsigned", "NO", nil, "", nil, nil, "", nil, ""},
+					{"entry", "user_id", "bigint unsigned", "NO", nil, "", nil, nil, "", nil, ""},
+					{"entry", "body", "text", "YES", nil, "", "utf8mb4", "utf8mb4_general_ci", "", nil, ""},
+					{"user", "id", "bigint unsigned", "NO", nil, "auto_increment", nil, nil, "", nil, ""},
+					{"user", "name", "varchar(191)", "NO", "John Doe", "", "utf8mb4", "utf8mb4_bin", "user's name", nil, ""},
+					{"user", "active", "tinyint(1)", "NO", "1", "", nil, nil, "", nil, ""},
+					{"user", "price", "decimal(9,6)", "NO", "0.000000", "", nil, nil, "", nil, ""},
+					{"user", "location", "point", "NO", nil, "INVISIBLE", nil, nil, "", int64(4326), ""},
+					{"user", "created_at", "datetime(6)", "NO", "CURRENT_TIMESTAMP(6)", "DEFAULT_GENERATED", nil, nil, "", nil, ""},
+					{"user", "updated_at", "datetime(6)", "NO", "CURRENT_TIMESTAMP(6)", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP(6)", nil, nil, "", nil, ""},
+					{"user", "name_length", "int", "YES", nil, "STORED GENERATED", nil, nil, "", nil, "char_length(`name`)"},
+				},
+			},
+			{
+				query: "`information_schema`.`STATISTICS`",
+				columns: []string{
+					"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME", "INDEX_TYPE", "INDEX_COMMENT", "IS_VISIBLE",
+					"SUB_PART", "COLLATION", "EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					{"entry", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+					{"entry", "idx_body", int64(1), "body", "FULLTEXT", "", "YES", nil, nil, nil},
+					{"entry", "idx_user", int64(1), "user_id", "BTREE", "", "YES", nil, "A", nil},
+					{"entry", "idx_user", int64(1), "id", "BTREE", "", "YES", nil, "D", nil},
+					{"user", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+					{"user", "idx_location", int64(1), "location", "SPATIAL", "", "YES", int64(32), "A", nil},
+					{"user", "idx_lower_name", int64(1), nil, "BTREE", "", "YES", nil, "A", "lower(`name`)"},
+					{"user", "idx_name_prefix", int64(1), "name", "BTREE", "", "YES", int64(16), "A", nil},
+					{"user", "uniq_name", int64(0), "name", "BTREE", "unique name", "NO", nil, "A", nil},
+				},
+			},
+			{
+				query:   "`information_schema`.`KEY_COLUMN_USAGE`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "UPDATE_RULE", "DELETE_RULE"},
+				rows: [][]driver.Value{
+					{"entry", "fk_user", "user_id", "user", "id", "NO ACTION", "CASCADE"},
+				},
+			},
+			{
+				query:   "`information_schema`.`CHECK_CONSTRAINTS`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"},
+				rows: [][]driver.Value{
+					{"user", "chk_active", "(`active` in (0,1))", "YES"},
+					{"user", "chk_price", "(`price` >= 0)", "NO"},
+				},
+			},
+			{
+				query:   "`information_schema`.`PARTITIONS`",
+				columns: []string{"TABLE_NAME", "PARTITION_NAME", "SUBPARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "PARTITION_DESCRIPTION", "PARTITION_COMMENT"},
+			},
+		},
+	})
+	defer db.Close()
+
+	schema, err := LoadSchema(context.Background(), db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*table{
+		{
+			name:    "entry",
+			rawName: "Entry",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", unsigned: true},
+				{name: "user_id", rawName: "UserID", typ: "BIGINT", unsigned: true},
+				{name: "body", rawName: "Body", typ: "TEXT", null: true, collate: "utf8mb4_general_ci"},
+			},
+			primaryKey: NewPrimaryKey("id"),
+			indexes: []*Index{
+				NewIndex("idx_user", "user_id", "id DESC"),
+			},
+			fullTextIndexes: []*FullTextIndex{
+				NewFullTextIndex("idx_body", "body"),
+			},
+			foreignKeys: []*ForeignKey{
+				NewForeignKey("fk_user", []string{"user_id"}, "user", []string{"id"}).OnDelete(ForeignKeyOptionCascade),
+			},
+			engine:       "InnoDB",
+			charset:      "utf8mb4",
+			collate:      "utf8mb4_bin",
+			rowFormat:    "COMPRESSED",
+			keyBlockSize: 8,
+			comment:      "entries",
+		},
+		{
+			name:    "user",
+			rawName: "User",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", unsigned: true, autoIncr: true},
+				{name: "name", rawName: "Name", typ: "VARCHAR", size: 191, def: "'John Doe'", comment: "user's name"},
+				{name: "active", rawName: "Active", typ: "TINYINT", size: 1, def: "1"},
+				{name: "price", rawName: "Price", typ: "DECIMAL", precision: 9, scale: 6, def: "0.000000"},
+				{name: "location", rawName: "Location", typ: "POINT", invisible: true, srid: 4326},
+				{name: "created_at", rawName: "CreatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)"},
+				{name: "updated_at", rawName: "UpdatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", onUpdate: "CURRENT_TIMESTAMP(6)"},
+				{name: "name_length", rawName: "NameLength", typ: "INTEGER", null: true, generated: "char_length(`name`)", stored: true},
+			},
+			primaryKey: NewPrimaryKey("id"),
+			indexes: []*Index{
+				NewIndex("idx_lower_name", "(lower(`name`))"),
+				NewIndex("idx_name_prefix", "name(16)"),
+			},
+			uniqueIndexes: []*UniqueIndex{
+				NewUniqueIndex("uniq_name", "name").Comment("unique name").Invisible(),
+			},
+			spatialIndexes: []*SpatialIndex{
+				NewSpatialIndex("idx_location", "location"),
+			},
+			checks: []*CheckConstraint{
+				NewCheck("chk_active", "`active` in (0,1)"),
+				NewCheck("chk_price", "`price` >= 0").NotEnforced(),
+			},
+			engine:  "InnoDB",
+			charset: "utf8mb4",
+			collate: "utf8mb4_bin",
+		},
+	}
+	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, keyPart{}, ForeignKey{}, CheckConstraint{}, FullTextIndex{}, SpatialIndex{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, schema.tables, opt1, opt2); diff != "" {
+		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
+	}
+
+	wantSequences := []*Sequence{
+		NewSequence("seq_order").Start(1000).MinValue(1000).MaxValue(9223372036854775806).Increment(10).Cache(20),
+	}
+	if diff := cmp.Diff(wantSequences, schema.sequences, cmp.AllowUnexported(Sequence{})); diff != "" {
+		t.Errorf("sequences are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestLoadSchema_Partitions(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{
+		results: []fakeResult{
+			{
+				query:   "SELECT VERSION()",
+				columns: []string{"VERSION()"},
+				rows:    [][]driver.Value{{"8.0.31"}},
+			},
+			{
+				query:   "`TABLE_TYPE` = 'SEQUENCE'",
+				columns: []string{"TABLE_NAME"},
+			},
+			{
+				query:   "`information_schema`.`TABLES`",
+				columns: []string{"TABLE_NAME", "ENGINE", "TABLE_COLLATION", "CHARACTER_SET_NAME", "CREATE_OPTIONS", "TABLE_COMMENT"},
+				rows: [][]driver.Value{
+					{"log", "InnoDB", "utf8mb4_bin", "utf8mb4", "partitioned", ""},
+					{"session", "InnoDB", "utf8mb4_bin", "utf8mb4", "partitioned", ""},
+				},
+			},
+			{
+				query: "`information_schema`.`COLUMNS`",
+				columns: []string{
+					"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA",
+					"CHARACTER_SET_NAME", "COLLATION_NAME", "COLUMN_COMMENT", "SRS_ID", "GENERATION_EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					{"log", "id", "bigint", "NO", nil, "", nil, nil, "", nil, ""},
+					{"log", "created_at", "datetime", "NO", nil, "", nil, nil, "", nil, ""},
+					{"session", "id", "bigint", "NO", nil, "", nil, nil, "", nil, ""},
+				},
+			},
+			{
+				query: "`information_schema`.`STATISTICS`",
+				columns: []string{
+					"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME", "INDEX_TYPE", "INDEX_COMMENT", "IS_VISIBLE",
+					"SUB_PART", "COLLATION", "EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					{"log", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+					{"log", "PRIMARY", int64(0), "created_at", "BTREE", "", "YES", nil, "A", nil},
+					{"session", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+				},
+			},
+			{
+				query:   "`information_schema`.`KEY_COLUMN_USAGE`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "UPDATE_RULE", "DELETE_RULE"},
+			},
+			{
+				query:   "`information_schema`.`CHECK_CONSTRAINTS`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"},
+			},
+			{
+				query:   "`information_schema`.`PARTITIONS`",
+				columns: []string{"TABLE_NAME", "PARTITION_NAME", "SUBPARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "PARTITION_DESCRIPTION", "PARTITION_COMMENT"},
+				rows: [][]driver.Value{
+					{"log", "p2022", nil, "RANGE COLUMNS", "`created_at`", "'2023-01-01 00:00:00'", ""},
+					{"log", "pmax", nil, "RANGE COLUMNS", "`created_at`", "MAXVALUE", "the rest"},
+					{"session", "p0", nil, "LINEAR KEY", "`id`", nil, ""},
+					{"session", "p1", nil, "LINEAR KEY", "`id`", nil, ""},
+				},
+			},
+		},
+	})
+	defer db.Close()
+
+	schema, err := LoadSchema(context.Background(), db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Partitions{
+		NewRangeColumnsPartitions("created_at").Add(
+			NewPartition("p2022").LessThan("'2023-01-01 00:00:00'"),
+			NewPartition("pmax").LessThan("MAXVALUE").Comment("the rest"),
+		),
+		NewKeyPartitions("id").Linear().Count(2),
+	}
+	var got []*Partitions
+	for _, table := range schema.tables {
+		got = append(got, table.partitions)
+	}
+	opt := cmp.AllowUnexported(Partitions{}, Partition{})
+	if diff := cmp.Diff(want, got, opt); diff != "" {
+		t.Errorf("partitions are not match (-want/+got):\n%s", diff)
+	}
+}
+
+type IntrospectUser struct {
+	ID uint64
+}
+
+func (*IntrospectUser) Table() string {
+	return "user"
+}
+
+func (*IntrospectUser) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type IntrospectEntry struct {
+	ID     uint64
+	UserID uint64
+}
+
+func (*IntrospectEntry) Table() string {
+	return "entry"
+}
+
+func (*IntrospectEntry) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*IntrospectEntry) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_user_id", "user_id"),
+	}
+}
+
+func (*IntrospectEntry) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_user", []string{"user_id"}, "user", []string{"id"}),
+	}
+}
+
+func TestLoadSchema_ImplicitIndexes(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{
+		results: []fakeResult{
+			{
+				query:   "SELECT VERSION()",
+				columns: []string{"VERSION()"},
+				rows:    [][]driver.Value{{"8.0.31"}},
+			},
+			{
+				query:   "`TABLE_TYPE` = 'SEQUENCE'",
+				columns: []string{"TABLE_NAME"},
+			},
+			{
+				query:   "`information_schema`.`TABLES`",
+				columns: []string{"TABLE_NAME", "ENGINE", "TABLE_COLLATION", "CHARACTER_SET_NAME", "CREATE_OPTIONS", "TABLE_COMMENT"},
+				rows: [][]driver.Value{
+					{"entry", nil, nil, nil, "", ""},
+					{"user", nil, nil, nil, "", ""},
+				},
+			},
+			{
+				query: "`information_schema`.`COLUMNS`",
+				columns: []string{
+					"TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "EXTRA",
+					"CHARACTER_SET_NAME", "COLLATION_NAME", "COLUMN_COMMENT", "SRS_ID", "GENERATION_EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					{"entry", "id", "bigint unsigned", "NO", nil, "", nil, nil, "", nil, ""},
+					{"entry", "user_id", "bigint unsigned", "NO", nil, "", nil, nil, "", nil, ""},
+					{"user", "id", "bigint unsigned", "NO", nil, "", nil, nil, "", nil, ""},
+				},
+			},
+			{
+				query: "`information_schema`.`STATISTICS`",
+				columns: []string{
+					"TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "COLUMN_NAME", "INDEX_TYPE", "INDEX_COMMENT", "IS_VISIBLE",
+					"SUB_PART", "COLLATION", "EXPRESSION",
+				},
+				rows: [][]driver.Value{
+					// MySQL created fk_user for the foreign key.
+					{"entry", "fk_user", int64(1), "user_id", "BTREE", "", "YES", nil, "A", nil},
+					{"entry", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+					{"user", "PRIMARY", int64(0), "id", "BTREE", "", "YES", nil, "A", nil},
+				},
+			},
+			{
+				query:   "`information_schema`.`KEY_COLUMN_USAGE`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "UPDATE_RULE", "DELETE_RULE"},
+				rows: [][]driver.Value{
+					{"entry", "fk_user", "user_id", "user", "id", "NO ACTION", "NO ACTION"},
+				},
+			},
+			{
+				query:   "`information_schema`.`CHECK_CONSTRAINTS`",
+				columns: []string{"TABLE_NAME", "CONSTRAINT_NAME", "CHECK_CLAUSE", "ENFORCED"},
+			},
+			{
+				query:   "`information_schema`.`PARTITIONS`",
+				columns: []string{"TABLE_NAME", "PARTITION_NAME", "SUBPARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "PARTITION_DESCRIPTION", "PARTITION_COMMENT"},
+			},
+		},
+	})
+	defer db.Close()
+
+	schema, err := LoadSchema(context.Background(), db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&IntrospectEntry{}, &IntrospectUser{})
+
+	// the implicit index fk_user is not dropped.
+	var buf bytes.Buffer
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	want := "ALTER TABLE `entry` ADD INDEX `idx_user_id` (`user_id`);\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestLoadSchema_MariaDB(t *testing.T) {
+	db := sql.OpenDB(&fakeConnector{
+		results: []fakeResult{
+			{
+				query:   "SELECT VERSION()",
+				columns: []string{"VERSION()"},
+				rows:    [][]driver.Value{{"10.11.2-MariaDB-1:10.11.2+maria~ubu2204"}},
+			},
+			{
+				query:   "`TABLE_TYPE` = 'SEQUENCE'",
+				columns: []string{"TABLE_NAME"},
+				rows:    [][]driver.Value{{"seq_order"}},
+			},
+			{
+				query:   "SHOW CREATE SEQUENCE `test`.`seq_order`",
+				columns: []string{"Table", "Create Table"},
+				rows: [][]driver.Value{
+					{"seq_order", "CREATE SEQUENCE `seq_order` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB"},
+				},
+			},
+			{
+				query:   "`information_schema`.`TABLES`",
+				columns: []string{"TABLE_NAME", "ENGINE", "TABLE_COLLATION", "CHARACTER_SET_NAME", "CREATE_OPTIONS", "TABLE_COMMENT"},
+				rows: [][]driver.Value{
+					{"user", "InnoDB", "utf8mb4_bin", "utf8mb4", "", ""},
+				},
+			},
+			{
+				query:   "SHOW CREATE TABLE `test`.`user`",
+				columns: []string{"Table", "Create Table"},
+				rows: [][]driver.Value{
+					{"user", "CREATE TABLE `user` (\n" +
+						"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,\n" +
+						"  `name` varchar(191) NOT NULL DEFAULT 'John Doe' COMMENT 'user''s name',\n" +
+						"  `attrs` longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL CHECK (json_valid(`attrs`)),\n" +
+						"  `created_at` datetime NOT NULL DEFAULT current_timestamp(),\n" +
+						"  `updated_at` datetime(6) NOT NULL DEFAULT current_timestamp(6) ON UPDATE current_timestamp(6),\n" +
+						"  `name_length` int(11) GENERATED ALWAYS AS (char_length(`name`)) VIRTUAL,\n" +
+						"  PRIMARY KEY (`id`),\n" +
+						"  UNIQUE KEY `uniq_name` (`name`) IGNORED\n" +
+						") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"},
+				},
+			},
+		},
+	})
+	defer db.Close()
+
+	schema, err := LoadSchema(context.Background(), db, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*table{
+		{
+			name:    "user",
+			rawName: "User",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", unsigned: true, autoIncr: true},
+				{name: "name", rawName: "Name", typ: "VARCHAR", size: 191, def: "'John Doe'", comment: "user's name"},
+				{name: "attrs", rawName: "Attrs", typ: "LONGTEXT", null: true, json: true, charset: "utf8mb4", collate: "utf8mb4_bin"},
+				{name: "created_at", rawName: "CreatedAt", typ: "DATETIME", def: "CURRENT_TIMESTAMP"},
+				{name: "updated_at", rawName: "UpdatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", onUpdate: "CURRENT_TIMESTAMP(6)"},
+				{name: "name_length", rawName: "NameLength", typ: "INTEGER", null: true, generated: "char_length(`name`)"},
+			},
+			primaryKey: NewPrimaryKey("id"),
+			uniqueIndexes: []*UniqueIndex{
+				NewUniqueIndex("uniq_name", "name").Invisible(),
+			},
+			engine:  "InnoDB",
+			charset: "utf8mb4",
+			collate: "utf8mb4_bin",
+		},
+	}
+	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, keyPart{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, schema.tables, opt1, opt2); diff != "" {
+		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
+	}
+
+	wantSequences := []*Sequence{
+		NewSequence("seq_order").Start(1).MinValue(1).MaxValue(9223372036854775806).Increment(1).Cache(1000),
+	}
+	if diff := cmp.Diff(wantSequences, schema.sequences, cmp.AllowUnexported(Sequence{})); diff != "" {
+		t.Errorf("sequences are not match (-want/+got):\n%s", diff)
+	}
+}
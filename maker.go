@@ -6,13 +6,19 @@ import (
 	"go/format"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config is a configuration of the DDL Maker.
 type Config struct {
 	DB *DBConfig
 
+	// Dialect is the SQL dialect of the database.
+	// If it is nil, DialectMySQL is used.
+	Dialect Dialect
+
 	// OutFilePath is a file path for SQL generated by the DDL Maker.
 	// If it is empty, "schema.sql" is used.
 	OutFilePath string
@@ -21,6 +27,18 @@ type Config struct {
 	// If it is empty, "schema_gen.go" is used.
 	OutGoFilePath string
 
+	// OutDir is a directory for SQL files of each table.
+	// If it is not empty, GenerateFile writes "<table>.sql" into the directory,
+	// and OutFilePath sources them in the order of the foreign key constraints.
+	// If MigrationDir is set, they are the baseline of the next migration.
+	OutDir string
+
+	// OutGoDir is a directory for Go source code of each table.
+	// If it is not empty, GenerateGoFile writes "<table>_gen.go" into the directory,
+	// and OutGoFilePath has the declarations that they share.
+	// It must be the same package as OutGoFilePath.
+	OutGoDir string
+
 	// PackageName is a package name for Go source code generated by the DDL Maker.
 	// If it is empty, "schema" is used.
 	PackageName string
@@ -31,6 +49,52 @@ type Config struct {
 
 	// SkipValidationFKIndex disables index validation for foreign key constraints.
 	SkipValidationFKIndex bool
+
+	// Bootstrap makes Generate emit CREATE DATABASE IF NOT EXISTS and CREATE TABLE IF NOT EXISTS without DROP TABLE.
+	// The generated SQL keeps the existing tables and their data,
+	// so it can run at every application start.
+	// CREATE DATABASE is emitted only if DBConfig.Name is set.
+	Bootstrap bool
+
+	// SkipDropTable omits DROP TABLE IF EXISTS from the SQL generated by Generate.
+	// It is always omitted in Bootstrap mode.
+	SkipDropTable bool
+
+	// KeepForeignKeyChecks makes Generate not change foreign_key_checks.
+	// The tables are dropped and created in the order of the foreign key constraints,
+	// so the generated DDL works on the databases that don't allow changing foreign_key_checks.
+	KeepForeignKeyChecks bool
+
+	// MigrationDir is a directory for migration files.
+	// If it is not empty, GenerateFile writes a new pair of up and down migration files into the directory,
+	// and OutFilePath keeps the whole schema as the baseline of the next migration.
+	MigrationDir string
+
+	// MigrationFormat is the naming convention of migration files.
+	// If it is empty, MigrationFormatGolangMigrate is used.
+	MigrationFormat MigrationFormat
+
+	// MigrationName is a description of migration files.
+	// If it is empty, "update_schema" is used.
+	MigrationName string
+
+	// AllowDestructive is the list of tables and columns that migrations may lose data of.
+	// The elements are table names "table" or column names "table.column".
+	// GenerateDiff and GenerateFile with MigrationDir refuse the changes that lose other data.
+	AllowDestructive []string
+
+	// OnlineDDL adds ALGORITHM and LOCK clauses to the ALTER TABLE statements that migrations generate.
+	// The statements fail instead of locking the tables if MySQL can't run them with the predicted algorithms.
+	// The prediction targets MySQL 8.0.29 or later.
+	OnlineDDL bool
+
+	// LargeTables is the list of tables that are too large to change by ALTER TABLE.
+	// Migrations write the command lines of OnlineSchemaChangeTool as comments for them.
+	LargeTables []string
+
+	// OnlineSchemaChangeTool is the tool that changes LargeTables.
+	// If it is empty, OnlineSchemaChangeToolGhost is used.
+	OnlineSchemaChangeTool OnlineSchemaChangeTool
 }
 
 type DBConfig struct {
@@ -42,12 +106,21 @@ type DBConfig struct {
 
 	// Collate is the default character collate for creating tables.
 	Collate string
+
+	// Name is the name of the database.
+	// It is passed to the online schema change tools,
+	// and Config.Bootstrap creates the database.
+	Name string
 }
 
 type Maker struct {
-	config  *Config
-	structs []any
-	tables  []*table
+	config    *Config
+	structs   []any
+	tables    []*table
+	sequences []*Sequence
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
 }
 
 func New(config *Config) (*Maker, error) {
@@ -63,11 +136,42 @@ func New(config *Config) (*Maker, error) {
 			Engine:  db.Engine,
 			Charset: db.Charset,
 			Collate: db.Collate,
+			Name:    db.Name,
 		},
+		Dialect:       config.Dialect,
 		OutFilePath:   withDefault(config.OutFilePath, "schema.sql"),
 		OutGoFilePath: withDefault(config.OutGoFilePath, "schema_gen.go"),
+		OutDir:        config.OutDir,
+		OutGoDir:      config.OutGoDir,
 		PackageName:   withDefault(config.PackageName, "schema"),
 		Tag:           withDefault(config.Tag, "myddlmaker"),
+
+		Bootstrap:            config.Bootstrap,
+		SkipDropTable:        config.SkipDropTable || config.Bootstrap,
+		KeepForeignKeyChecks: config.KeepForeignKeyChecks,
+
+		MigrationDir:    config.MigrationDir,
+		MigrationFormat: withDefault(config.MigrationFormat, MigrationFormatGolangMigrate),
+		MigrationName:   withDefault(config.MigrationName, "update_schema"),
+
+		AllowDestructive: config.AllowDestructive,
+		OnlineDDL:        config.OnlineDDL,
+
+		LargeTables:            config.LargeTables,
+		OnlineSchemaChangeTool: withDefault(config.OnlineSchemaChangeTool, OnlineSchemaChangeToolGhost),
+	}
+	if c.Dialect == nil {
+		c.Dialect = DialectMySQL
+	}
+	switch c.MigrationFormat {
+	case MigrationFormatGolangMigrate, MigrationFormatFlyway:
+	default:
+		return nil, fmt.Errorf("myddlmaker: unknown migration format: %q", c.MigrationFormat)
+	}
+	switch c.OnlineSchemaChangeTool {
+	case OnlineSchemaChangeToolGhost, OnlineSchemaChangeToolPtOSC:
+	default:
+		return nil, fmt.Errorf("myddlmaker: unknown online schema change tool: %q", c.OnlineSchemaChangeTool)
 	}
 	return &Maker{
 		config: c,
@@ -88,6 +192,16 @@ func (m *Maker) AddStructs(structs ...any) {
 
 // GenerateFile opens
 func (m *Maker) GenerateFile() error {
+	if m.config.MigrationDir != "" {
+		return m.GenerateMigrationFiles()
+	}
+	if m.config.OutDir != "" {
+		if err := m.generateSplitFiles(); err != nil {
+			return fmt.Errorf("myddlmaker: failed to generate ddl: %w", err)
+		}
+		return nil
+	}
+
 	f, err := os.Create(m.config.OutFilePath)
 	if err != nil {
 		return fmt.Errorf("myddlmaker: failed to open %q: %w", m.config.OutFilePath, err)
@@ -107,12 +221,25 @@ func (m *Maker) Generate(w io.Writer) error {
 		return err
 	}
 
-	buf.WriteString("SET foreign_key_checks=0;\n")
-	for _, table := range m.tables {
-		m.generateTable(&buf, table)
+	if m.config.Bootstrap {
+		m.generateCreateDatabase(&buf)
+	}
+	if m.config.KeepForeignKeyChecks {
+		m.generateSequences(&buf)
+		if err := m.generateOrdered(&buf); err != nil {
+			return err
+		}
+	} else {
+		buf.WriteString("SET foreign_key_checks=0;\n")
+		if len(m.sequences) > 0 {
+			buf.WriteString("\n")
+			m.generateSequences(&buf)
+		}
+		for _, table := range m.tables {
+			m.generateTable(&buf, table)
+		}
+		buf.WriteString("SET foreign_key_checks=1;\n")
 	}
-
-	buf.WriteString("SET foreign_key_checks=1;\n")
 
 	if _, err := buf.WriteTo(w); err != nil {
 		return err
@@ -120,10 +247,32 @@ func (m *Maker) Generate(w io.Writer) error {
 	return nil
 }
 
+// Schema is a set of parsed table and sequence definitions.
+// It is used as a baseline of [Maker.GenerateDiff].
+type Schema struct {
+	tables    []*table
+	sequences []*Sequence
+}
+
+// Schema parses the structs added by AddStructs and returns the schema.
+func (m *Maker) Schema() (*Schema, error) {
+	if err := m.parse(); err != nil {
+		return nil, err
+	}
+	tables := make([]*table, len(m.tables))
+	copy(tables, m.tables)
+	sequences := make([]*Sequence, len(m.sequences))
+	copy(sequences, m.sequences)
+	return &Schema{
+		tables:    tables,
+		sequences: sequences,
+	}, nil
+}
+
 func (m *Maker) parse() error {
 	m.tables = make([]*table, len(m.structs))
 	for i, s := range m.structs {
-		tbl, err := newTable(s)
+		tbl, err := newTable(s, m.config.Dialect)
 		if err != nil {
 			return fmt.Errorf("myddlmaker: failed to parse: %w", err)
 		}
@@ -138,164 +287,210 @@ func (m *Maker) parse() error {
 func (m *Maker) validate() error {
 	v := newValidator(m.tables)
 	v.SkipValidationFKIndex = m.config.SkipValidationFKIndex
+	v.DB = m.config.DB
+	v.Dialect = m.config.Dialect
+	v.Sequences = m.sequences
 	return v.Validate()
 }
 
+// generateCreateDatabase writes the CREATE DATABASE statement and switches to the database.
+func (m *Maker) generateCreateDatabase(w io.Writer) {
+	db := m.config.DB
+	if db == nil || db.Name == "" {
+		return
+	}
+	fmt.Fprintf(w, "CREATE DATABASE IF NOT EXISTS %s", quote(db.Name))
+	if db.Charset != "" {
+		fmt.Fprintf(w, " DEFAULT CHARACTER SET=%s", db.Charset)
+	}
+	if db.Collate != "" {
+		fmt.Fprintf(w, " DEFAULT COLLATE=%s", db.Collate)
+	}
+	fmt.Fprintf(w, ";\n\nUSE %s;\n\n", quote(db.Name))
+}
+
 func (m *Maker) generateTable(w io.Writer, table *table) {
-	fmt.Fprintf(w, "\nDROP TABLE IF EXISTS %s;\n\n", quote(table.name))
-	fmt.Fprintf(w, "CREATE TABLE %s (\n", quote(table.name))
+	if m.config.SkipDropTable {
+		io.WriteString(w, "\n")
+	} else {
+		fmt.Fprintf(w, "\nDROP TABLE IF EXISTS %s;\n\n", quote(table.name))
+	}
+	m.generateCreateTable(w, table, m.config.Bootstrap)
+	fmt.Fprintf(w, ";\n\n")
+}
+
+// generateCreateTable writes the CREATE TABLE statement of the table without the trailing semicolon.
+// If ifNotExists is true, the statement doesn't fail if the table already exists.
+func (m *Maker) generateCreateTable(w io.Writer, table *table, ifNotExists bool) {
+	if ifNotExists {
+		fmt.Fprintf(w, "CREATE TABLE IF NOT EXISTS %s (\n", quote(table.name))
+	} else {
+		fmt.Fprintf(w, "CREATE TABLE %s (\n", quote(table.name))
+	}
 	for _, col := range table.columns {
 		m.generateColumn(w, col)
 	}
 	m.generateIndex(w, table)
-	fmt.Fprintf(w, "    PRIMARY KEY (%s)\n", strings.Join(quoteAll(table.primaryKey.columns), ", "))
+	io.WriteString(w, "    ")
+	m.generatePrimaryKeyDefinition(w, table.primaryKey)
+	io.WriteString(w, "\n")
 
 	fmt.Fprintf(w, ")")
-	if m.config != nil && m.config.DB != nil {
-		if engine := m.config.DB.Engine; engine != "" {
-			fmt.Fprintf(w, " ENGINE=%s", engine)
-		}
-		if charset := m.config.DB.Charset; charset != "" {
-			fmt.Fprintf(w, " DEFAULT CHARACTER SET=%s", charset)
-		}
-		if collate := m.config.DB.Collate; collate != "" {
-			fmt.Fprintf(w, " DEFAULT COLLATE=%s", collate)
-		}
+	m.generateTableOptions(w, table)
+	if table.partitions != nil {
+		m.generatePartitions(w, table.partitions)
 	}
-	fmt.Fprintf(w, ";\n\n")
 }
 
-func (m *Maker) generateColumn(w io.Writer, col *column) {
-	io.WriteString(w, "    ")
-	io.WriteString(w, quote(col.name))
-	io.WriteString(w, " ")
-	io.WriteString(w, col.typ)
-	if col.size != 0 {
-		fmt.Fprintf(w, "(%d)", col.size)
+func (m *Maker) generateTableOptions(w io.Writer, table *table) {
+	for _, opt := range m.config.Dialect.tableOptions(table, m.config.DB) {
+		io.WriteString(w, " ")
+		io.WriteString(w, opt.sql)
 	}
-	if col.charset != "" {
-		io.WriteString(w, " CHARACTER SET ")
-		io.WriteString(w, col.charset)
-	}
-	if col.collate != "" {
-		io.WriteString(w, " COLLATE ")
-		io.WriteString(w, col.collate)
-	}
-	if col.unsigned {
-		io.WriteString(w, " UNSIGNED")
+}
+
+func (m *Maker) generatePartitions(w io.Writer, p *Partitions) {
+	io.WriteString(w, "\nPARTITION BY ")
+	if p.linear {
+		io.WriteString(w, "LINEAR ")
 	}
-	if col.null {
-		io.WriteString(w, " NULL")
+	io.WriteString(w, p.typ)
+	if p.expr != "" {
+		fmt.Fprintf(w, " (%s)", p.expr)
+	} else if p.typ == "KEY" {
+		fmt.Fprintf(w, " (%s)", strings.Join(quoteAll(p.columns), ", "))
 	} else {
-		io.WriteString(w, " NOT NULL")
-	}
-	if col.def != "" {
-		io.WriteString(w, " DEFAULT ")
-		io.WriteString(w, col.def)
+		fmt.Fprintf(w, " COLUMNS (%s)", strings.Join(quoteAll(p.columns), ", "))
 	}
-	if col.invisible {
-		// https://dev.mysql.com/doc/refman/8.0/en/invisible-columns.html
-		io.WriteString(w, " INVISIBLE")
+	if p.count > 0 {
+		fmt.Fprintf(w, " PARTITIONS %d", p.count)
 	}
-	if col.autoIncr {
-		io.WriteString(w, " AUTO_INCREMENT")
+	if len(p.definitions) == 0 {
+		return
 	}
-	if col.comment != "" {
-		io.WriteString(w, " COMMENT ")
-		io.WriteString(w, stringQuote(col.comment))
+	io.WriteString(w, " (\n")
+	for i, def := range p.definitions {
+		fmt.Fprintf(w, "    PARTITION %s VALUES %s (%s)", quote(def.name), def.op, strings.Join(def.values, ", "))
+		if def.comment != "" {
+			io.WriteString(w, " COMMENT ")
+			io.WriteString(w, stringQuote(def.comment))
+		}
+		if i < len(p.definitions)-1 {
+			io.WriteString(w, ",")
+		}
+		io.WriteString(w, "\n")
 	}
+	io.WriteString(w, ")")
+}
+
+func (m *Maker) generateColumn(w io.Writer, col *column) {
+	io.WriteString(w, "    ")
+	m.generateColumnDefinition(w, col)
 	io.WriteString(w, ",\n")
 }
 
+func (m *Maker) generateColumnDefinition(w io.Writer, col *column) {
+	m.config.Dialect.generateColumnDefinition(w, col)
+}
+
 func (m *Maker) generateIndex(w io.Writer, table *table) {
 	for _, idx := range table.indexes {
-		io.WriteString(w, "    INDEX ")
-		io.WriteString(w, quote(idx.name))
-		io.WriteString(w, " (")
-		io.WriteString(w, strings.Join(quoteAll(idx.columns), ", "))
-		io.WriteString(w, ")")
-		if idx.invisible {
-			io.WriteString(w, " INVISIBLE")
-		}
-		if idx.comment != "" {
-			io.WriteString(w, " COMMENT ")
-			io.WriteString(w, stringQuote(idx.comment))
-		}
+		io.WriteString(w, "    ")
+		m.generateIndexDefinition(w, idx)
 		io.WriteString(w, ",\n")
 	}
 
 	for _, idx := range table.uniqueIndexes {
-		io.WriteString(w, "    UNIQUE ")
-		io.WriteString(w, quote(idx.name))
-		io.WriteString(w, " (")
-		io.WriteString(w, strings.Join(quoteAll(idx.columns), ", "))
-		io.WriteString(w, ")")
-		if idx.invisible {
-			io.WriteString(w, " INVISIBLE")
-		}
-		if idx.comment != "" {
-			io.WriteString(w, " COMMENT ")
-			io.WriteString(w, stringQuote(idx.comment))
-		}
+		io.WriteString(w, "    ")
+		m.generateUniqueIndexDefinition(w, idx)
 		io.WriteString(w, ",\n")
 	}
 
 	for _, idx := range table.fullTextIndexes {
-		io.WriteString(w, "    FULLTEXT INDEX ")
-		io.WriteString(w, quote(idx.name))
-		io.WriteString(w, " (")
-		io.WriteString(w, quote(idx.column))
-		io.WriteString(w, ")")
-		if idx.invisible {
-			io.WriteString(w, " INVISIBLE")
-		}
-		if idx.parser != "" {
-			io.WriteString(w, " WITH PARSER ")
-			io.WriteString(w, idx.parser)
-		}
-		if idx.comment != "" {
-			io.WriteString(w, " COMMENT ")
-			io.WriteString(w, stringQuote(idx.comment))
-		}
+		io.WriteString(w, "    ")
+		m.generateFullTextIndexDefinition(w, idx)
 		io.WriteString(w, ",\n")
 	}
 
 	for _, idx := range table.spatialIndexes {
-		io.WriteString(w, "    SPATIAL INDEX ")
-		io.WriteString(w, quote(idx.name))
-		io.WriteString(w, " (")
-		io.WriteString(w, quote(idx.column))
-		io.WriteString(w, ")")
-		if idx.invisible {
-			io.WriteString(w, " INVISIBLE")
-		}
-		if idx.comment != "" {
-			io.WriteString(w, " COMMENT ")
-			io.WriteString(w, stringQuote(idx.comment))
-		}
+		io.WriteString(w, "    ")
+		m.generateSpatialIndexDefinition(w, idx)
 		io.WriteString(w, ",\n")
 	}
 
-	for _, idx := range table.foreignKeys {
-		io.WriteString(w, "    CONSTRAINT ")
-		io.WriteString(w, quote(idx.name))
-		io.WriteString(w, " FOREIGN KEY (")
-		io.WriteString(w, strings.Join(quoteAll(idx.columns), ", "))
-		io.WriteString(w, ") REFERENCES ")
-		io.WriteString(w, quote(idx.table))
-		io.WriteString(w, " (")
-		io.WriteString(w, strings.Join(quoteAll(idx.references), ", "))
-		io.WriteString(w, ")")
-		if idx.onDelete != "" {
-			io.WriteString(w, " ON DELETE ")
-			io.WriteString(w, string(idx.onDelete))
-		}
-		if idx.onUpdate != "" {
-			io.WriteString(w, " ON UPDATE ")
-			io.WriteString(w, string(idx.onUpdate))
-		}
+	for _, idx := range table.multiValuedIndexes {
+		io.WriteString(w, "    ")
+		m.generateIndexDefinition(w, idx.index())
+		io.WriteString(w, ",\n")
+	}
+
+	for _, fk := range table.foreignKeys {
+		io.WriteString(w, "    ")
+		m.generateForeignKeyDefinition(w, fk)
 		io.WriteString(w, ",\n")
 	}
+
+	for _, chk := range table.checks {
+		io.WriteString(w, "    ")
+		m.generateCheckDefinition(w, chk)
+		io.WriteString(w, ",\n")
+	}
+}
+
+func (m *Maker) generatePrimaryKeyDefinition(w io.Writer, pk *PrimaryKey) {
+	fmt.Fprintf(w, "PRIMARY KEY (%s)", strings.Join(quoteAll(pk.columns), ", "))
+	if pk.clustered != "" {
+		// https://docs.pingcap.com/tidb/stable/clustered-indexes
+		io.WriteString(w, " ")
+		io.WriteString(w, pk.clustered)
+	}
+}
+
+func (m *Maker) generateIndexDefinition(w io.Writer, idx *Index) {
+	m.config.Dialect.generateIndexDefinition(w, idx)
+}
+
+func (m *Maker) generateUniqueIndexDefinition(w io.Writer, idx *UniqueIndex) {
+	m.config.Dialect.generateUniqueIndexDefinition(w, idx)
+}
+
+func (m *Maker) generateFullTextIndexDefinition(w io.Writer, idx *FullTextIndex) {
+	m.config.Dialect.generateFullTextIndexDefinition(w, idx)
+}
+
+func (m *Maker) generateSpatialIndexDefinition(w io.Writer, idx *SpatialIndex) {
+	m.config.Dialect.generateSpatialIndexDefinition(w, idx)
+}
+
+func (m *Maker) generateForeignKeyDefinition(w io.Writer, fk *ForeignKey) {
+	io.WriteString(w, "CONSTRAINT ")
+	io.WriteString(w, quote(fk.name))
+	io.WriteString(w, " FOREIGN KEY (")
+	io.WriteString(w, strings.Join(quoteAll(fk.columns), ", "))
+	io.WriteString(w, ") REFERENCES ")
+	io.WriteString(w, quote(fk.table))
+	io.WriteString(w, " (")
+	io.WriteString(w, strings.Join(quoteAll(fk.references), ", "))
+	io.WriteString(w, ")")
+	if fk.onDelete != "" {
+		io.WriteString(w, " ON DELETE ")
+		io.WriteString(w, string(fk.onDelete))
+	}
+	if fk.onUpdate != "" {
+		io.WriteString(w, " ON UPDATE ")
+		io.WriteString(w, string(fk.onUpdate))
+	}
+}
+
+func (m *Maker) generateCheckDefinition(w io.Writer, chk *CheckConstraint) {
+	io.WriteString(w, "CONSTRAINT ")
+	io.WriteString(w, quote(chk.name))
+	io.WriteString(w, " CHECK (")
+	io.WriteString(w, chk.expr)
+	io.WriteString(w, ")")
+	if chk.notEnforced {
+		io.WriteString(w, " NOT ENFORCED")
+	}
 }
 
 // quote quotes s with `s`.
@@ -351,8 +546,18 @@ func stringQuote(s string) string {
 	return buf.String()
 }
 
+// formatValues returns the permitted values of ENUM and SET, e.g. ('active','inactive').
+func formatValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, stringQuote(v))
+	}
+	return "(" + strings.Join(quoted, ",") + ")"
+}
+
 type PrimaryKey struct {
-	columns []string
+	columns   []string
+	clustered string
 }
 
 type primaryKey interface {
@@ -365,7 +570,30 @@ func NewPrimaryKey(field ...string) *PrimaryKey {
 	}
 }
 
+// Clustered returns a copy of pk, but TiDB stores the rows in the order of the primary key.
+// It is available only for DialectTiDB.
+func (pk *PrimaryKey) Clustered() *PrimaryKey {
+	tmp := *pk // shallow copy
+	tmp.clustered = "CLUSTERED"
+	return &tmp
+}
+
+// NonClustered returns a copy of pk, but TiDB stores the rows in the order of the internal row id.
+// It is available only for DialectTiDB.
+func (pk *PrimaryKey) NonClustered() *PrimaryKey {
+	tmp := *pk // shallow copy
+	tmp.clustered = "NONCLUSTERED"
+	return &tmp
+}
+
 func (m *Maker) GenerateGoFile() error {
+	if m.config.OutGoDir != "" {
+		if err := m.generateGoSplitFiles(); err != nil {
+			return fmt.Errorf("myddlmaker: failed to generate go file: %w", err)
+		}
+		return nil
+	}
+
 	f, err := os.Create(m.config.OutGoFilePath)
 	if err != nil {
 		return fmt.Errorf("myddlmaker: failed to open %q: %w", m.config.OutGoFilePath, err)
@@ -385,7 +613,7 @@ func (m *Maker) GenerateGo(w io.Writer) error {
 		return err
 	}
 
-	m.generateGoHeader(&buf)
+	m.generateGoHeader(&buf, m.tables, true)
 	for _, table := range m.tables {
 		m.generateGoTable(&buf, table)
 	}
@@ -398,14 +626,49 @@ func (m *Maker) GenerateGo(w io.Writer) error {
 	return err
 }
 
-func (m *Maker) generateGoHeader(w io.Writer) {
-	io.WriteString(w, "// Code generated by https://github.com/shogo82148/myddlmaker; DO NOT EDIT.\n\n")
+// goHeader is the first line of the generated Go source code.
+const goHeader = "// Code generated by https://github.com/shogo82148/myddlmaker; DO NOT EDIT."
+
+// generateGoHeader writes the package clause and the imports that the code of the tables uses.
+// If interfaces is true, it also writes the interfaces that the functions of the tables share.
+func (m *Maker) generateGoHeader(w io.Writer, tables []*table, interfaces bool) {
+	io.WriteString(w, goHeader)
+	io.WriteString(w, "\n\n")
 	fmt.Fprintf(w, "//go:build !%s\n\n", m.config.Tag)
 	fmt.Fprintf(w, "package %s\n\n", m.config.PackageName)
-	fmt.Fprintf(w, `import (
-		"context"
-		"database/sql"
-	)
+
+	// fmt and strings are used for validating the values of ENUM and SET.
+	var imports []string
+	if interfaces || len(tables) > 0 {
+		imports = append(imports, "context")
+	}
+	if interfaces {
+		imports = append(imports, "database/sql")
+	}
+	var hasEnum, hasSet bool
+	for _, table := range tables {
+		for _, col := range valuesColumns(table) {
+			hasEnum = true
+			if col.typ == "SET" {
+				hasSet = true
+			}
+		}
+	}
+	if hasEnum {
+		imports = append(imports, "fmt")
+	}
+	if hasSet {
+		imports = append(imports, "strings")
+	}
+	io.WriteString(w, "import (\n")
+	for _, pkg := range imports {
+		fmt.Fprintf(w, "%q\n", pkg)
+	}
+	io.WriteString(w, ")\n")
+	if !interfaces {
+		return
+	}
+	fmt.Fprintf(w, `
 
 	type execer interface {
 		ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
@@ -422,24 +685,96 @@ func (m *Maker) generateGoHeader(w io.Writer) {
 }
 
 func (m *Maker) generateGoTable(w io.Writer, table *table) {
+	m.generateGoTableValidate(w, table)
 	m.generateGoTableInsert(w, table)
 	m.generateGoTableSelect(w, table)
 	m.generateGoTableSelectAll(w, table)
+	m.generateGoTableMemberOf(w, table)
 	m.generateGoTableUpdate(w, table)
 }
 
+// valuesColumns returns the ENUM and SET columns that the generated codes validate.
+// The generated codes compare the values as strings,
+// so the columns of the other kinds, e.g. sql.NullString, are not validated.
+func valuesColumns(table *table) []*column {
+	var columns []*column
+	for _, c := range table.columns {
+		if (c.typ == "ENUM" || c.typ == "SET") && len(c.values) > 0 && c.generated == "" && c.stringKind {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// generateGoTableValidate generates the function that rejects the values out of ENUM and SET.
+func (m *Maker) generateGoTableValidate(w io.Writer, table *table) {
+	columns := valuesColumns(table)
+	if len(columns) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "func validate%[1]s(v *%[1]s) error {\n", table.rawName)
+	for _, c := range columns {
+		cases := make([]string, 0, len(c.values))
+		for _, val := range c.values {
+			cases = append(cases, strconv.Quote(val))
+		}
+		value := "v." + c.rawName
+		if c.pointer {
+			fmt.Fprintf(w, "if %s != nil {\n", value)
+			value = "*" + value
+		}
+		if c.typ == "SET" {
+			fmt.Fprintf(w, "if %s != \"\" {\n", value)
+			fmt.Fprintf(w, "for _, s := range strings.Split(string(%s), \",\") {\n", value)
+			fmt.Fprintf(w, "switch s {\n")
+			fmt.Fprintf(w, "case %s:\n", strings.Join(cases, ", "))
+			fmt.Fprintf(w, "default:\n")
+			fmt.Fprintf(w, "return fmt.Errorf(%q, s)\n", "invalid value of "+quote(table.name)+"."+quote(c.name)+": %q")
+			fmt.Fprintf(w, "}\n")
+			fmt.Fprintf(w, "}\n")
+			fmt.Fprintf(w, "}\n")
+		} else {
+			fmt.Fprintf(w, "switch %s {\n", value)
+			fmt.Fprintf(w, "case %s:\n", strings.Join(cases, ", "))
+			fmt.Fprintf(w, "default:\n")
+			fmt.Fprintf(w, "return fmt.Errorf(%q, %s)\n", "invalid value of "+quote(table.name)+"."+quote(c.name)+": %q", value)
+			fmt.Fprintf(w, "}\n")
+		}
+		if c.pointer {
+			fmt.Fprintf(w, "}\n")
+		}
+	}
+	fmt.Fprintf(w, "return nil\n")
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// generateGoTableValidateCall generates the code that validates the values before executing the queries.
+func (m *Maker) generateGoTableValidateCall(w io.Writer, table *table) {
+	if len(valuesColumns(table)) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "for _, v := range values {\n")
+	fmt.Fprintf(w, "if err := validate%s(v); err != nil {\n", table.rawName)
+	fmt.Fprintf(w, "return err\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "}\n")
+}
+
 func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 	// https://stackoverflow.com/questions/18100782/import-of-50k-records-in-mysql-gives-general-error-1390-prepared-statement-con
 	const maxPlaceholderCount = 65535
 	const maxMaxStructCount = 32
 
 	fmt.Fprintf(w, "func Insert%[1]s(ctx context.Context, execer execer, values ...*%[1]s) error {", table.rawName)
+	m.generateGoTableValidateCall(w, table)
 
 	columns := make([]string, 0, len(table.columns))
 	placeholders := make([]string, 0, len(table.columns))
 	values := make([]string, 0, len(table.columns))
 	for _, c := range table.columns {
-		if c.autoIncr {
+		if c.autoIncr || c.autoRandom || c.generated != "" || c.autoNowAdd || c.autoNow {
+			// MySQL generates the values.
 			continue
 		}
 		columns = append(columns, quote(c.name))
@@ -447,6 +782,30 @@ func (m *Maker) generateGoTableInsert(w io.Writer, table *table) {
 		values = append(values, fmt.Sprintf("v.%s", c.rawName))
 	}
 
+	if len(placeholders) == 0 {
+		// the database generates all columns, so each row is inserted without values.
+		insert := "INSERT INTO " + quote(table.name) + " () VALUES ()"
+		fmt.Fprintf(w, "const q = %q\n", insert)
+		io.WriteString(w, `if len(values) == 0 {
+		return nil
+	}
+	stmt, err := execer.PrepareContext(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for range values {
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+`)
+		return
+	}
+
 	strPlaceholders := ", (" + strings.Join(placeholders, ", ") + ")"
 	maxStructCount := maxPlaceholderCount / len(placeholders)
 	if maxStructCount > maxMaxStructCount {
@@ -566,6 +925,53 @@ func (m *Maker) generateGoTableSelectAll(w io.Writer, table *table) {
 	fmt.Fprintf(w, "}\n\n")
 }
 
+// generateGoTableMemberOf generates the functions that look up the rows with the multi-valued indexes.
+func (m *Maker) generateGoTableMemberOf(w io.Writer, table *table) {
+	fields := make([]string, 0, len(table.columns))
+	goFields := make([]string, 0, len(table.columns))
+	columns := make(map[string]*column, len(table.columns))
+	for _, c := range table.columns {
+		fields = append(fields, quote(c.name))
+		goFields = append(goFields, "&v."+c.rawName)
+		columns[c.name] = c
+	}
+	keys := make([]string, 0, len(table.primaryKey.columns))
+	for _, key := range table.primaryKey.columns {
+		keys = append(keys, quote(key))
+	}
+
+	for _, idx := range table.multiValuedIndexes {
+		// use the Go type of the elements if it is a predeclared type,
+		// other types may need to be imported.
+		typ := "any"
+		if elem, ok := jsonArrayElem(columns[idx.column].rawType); ok && elem != nil && elem.PkgPath() == "" && elem.Name() != "" {
+			typ = elem.Name()
+		}
+
+		sqlSelect := fmt.Sprintf(
+			"SELECT %s FROM %s WHERE ? MEMBER OF(%s->%s) ORDER BY %s",
+			strings.Join(fields, ", "),
+			quote(table.name),
+			quote(idx.column),
+			stringQuote(idx.path),
+			strings.Join(keys, ", "),
+		)
+		fmt.Fprintf(w, "func Select%sBy%s(ctx context.Context, queryer queryer, value %s) ([]*%s, error) {\n", table.rawName, snakeToCamel(idx.name), typ, table.rawName)
+		fmt.Fprintf(w, "var ret []*%[1]s\n", table.rawName)
+		fmt.Fprintf(w, "rows, err := queryer.QueryContext(ctx, %q, value)\n", sqlSelect)
+		fmt.Fprintf(w, "if err != nil {\n return nil, err \n}\n")
+		fmt.Fprintf(w, "defer rows.Close()\n")
+		fmt.Fprintf(w, "for rows.Next() {\n")
+		fmt.Fprintf(w, "var v %s\n", table.rawName)
+		fmt.Fprintf(w, "if err := rows.Scan(%s); err != nil {\n return nil, err \n}\n", strings.Join(goFields, ", "))
+		fmt.Fprintf(w, "ret = append(ret, &v)")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "if err := rows.Err(); err != nil {\n return nil, err \n}\n")
+		fmt.Fprintf(w, "return ret, nil\n")
+		fmt.Fprintf(w, "}\n\n")
+	}
+}
+
 func (m *Maker) generateGoTableUpdate(w io.Writer, table *table) {
 	setFields := make([]string, 0, len(table.columns))
 	goFields := make([]string, 0, len(table.columns))
@@ -581,6 +987,10 @@ LOOP:
 				continue LOOP
 			}
 		}
+		if c.generated != "" || c.autoNowAdd || c.autoNow {
+			// MySQL generates the values.
+			continue
+		}
 		setFields = append(setFields, fmt.Sprintf("%s = ?", quote(c.name)))
 		goFields = append(goFields, "value."+c.rawName)
 	}
@@ -593,6 +1003,7 @@ LOOP:
 	)
 	fmt.Fprintf(w, "func Update%[1]s(ctx context.Context, execer execer, values ...*%[1]s) error {\n", table.rawName)
 	if len(setFields) != 0 {
+		m.generateGoTableValidateCall(w, table)
 		fmt.Fprintf(w, "stmt, err := execer.PrepareContext(ctx, %q)\n", update)
 		fmt.Fprintf(w, "if err != nil {\n")
 		fmt.Fprintf(w, "return err\n")
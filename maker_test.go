@@ -5,12 +5,14 @@ import (
 	"context"
 	"crypto/rand"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -318,6 +320,397 @@ func (*Foo20) PrimaryKey() *PrimaryKey {
 	return NewPrimaryKey("id")
 }
 
+type Foo21 struct {
+	ID        int64 `ddl:",auto"`
+	CreatedAt time.Time
+}
+
+func (*Foo21) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "created_at")
+}
+
+func (*Foo21) Partitions() *Partitions {
+	return NewRangeColumnsPartitions("created_at").Add(
+		NewPartition("p2022").LessThan("'2023-01-01 00:00:00'"),
+		NewPartition("pmax").LessThan("MAXVALUE").Comment("the rest"),
+	)
+}
+
+type Foo22 struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+}
+
+func (*Foo22) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "user_id")
+}
+
+func (*Foo22) Partitions() *Partitions {
+	return NewHashPartitions("`user_id` DIV 100").Linear().Count(4)
+}
+
+type Foo23 struct {
+	ID     int64 `ddl:",auto"`
+	UserID int64
+	Name   string
+}
+
+func (*Foo23) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo23) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_name", "name"),
+	}
+}
+
+func (*Foo23) Partitions() *Partitions {
+	return NewListPartitions("MOD(user_id, 2)")
+}
+
+type Foo24 struct {
+	ID       int32           `ddl:",auto"`
+	Doc      json.RawMessage `ddl:",type=JSON"`
+	DocID    int64           `ddl:",as=(JSON_EXTRACT(doc, '$.id')),stored"`
+	DocTitle sql.NullString  `ddl:",null,as=doc->>'$.title',virtual"`
+}
+
+func (*Foo24) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo24) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_doc_id", "doc_id"),
+	}
+}
+
+type Foo25 struct {
+	ID    int32 `ddl:",auto"`
+	Age   int32
+	Score int32
+}
+
+func (*Foo25) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo25) Checks() []*CheckConstraint {
+	return []*CheckConstraint{
+		NewCheck("chk_age", "`age` >= 0"),
+		NewCheck("chk_score", "`score` BETWEEN 0 AND 100").NotEnforced(),
+	}
+}
+
+type Foo26 struct {
+	ID int32
+}
+
+func (*Foo26) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo26) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_foo25", []string{"id"}, "foo25", []string{"id"}),
+	}
+}
+
+func (*Foo26) Checks() []*CheckConstraint {
+	return []*CheckConstraint{
+		// the names of constraints are unique in the schema.
+		NewCheck("chk_age", "`id` > 0"),
+		NewCheck("fk_foo25", "`id` > 0"),
+	}
+}
+
+type Foo27 struct {
+	ID        int32 `ddl:",auto"`
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+func (*Foo27) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo27) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_name_created_at", "name(32)", "created_at DESC"),
+	}
+}
+
+func (*Foo27) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_email", "(LOWER(`email`))"),
+	}
+}
+
+type Foo28 struct {
+	ID       int32
+	Foo27ID  int32
+	Foo27Key string
+}
+
+func (*Foo28) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo28) Indexes() []*Index {
+	return []*Index{
+		// prefix key parts can't be used by foreign keys.
+		NewIndex("idx_foo27_id", "foo27_id(8)"),
+		NewIndex("idx_foo27_key", "(UPPER(`unknown_column`))"),
+	}
+}
+
+func (*Foo28) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_foo27", []string{"foo27_id"}, "foo27", []string{"id"}),
+	}
+}
+
+type Foo29 struct {
+	ID    int32 `ddl:",auto"`
+	Tags  JSON[[]int64]
+	Names JSON[[]string]
+	Doc   json.RawMessage
+}
+
+func (*Foo29) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo29) MultiValuedIndexes() []*MultiValuedIndex {
+	return []*MultiValuedIndex{
+		NewMultiValuedIndex("idx_tags", "tags", "SIGNED"),
+		NewMultiValuedIndex("idx_names", "names", "CHAR(64)").Comment("names"),
+		NewMultiValuedIndex("idx_doc_zips", "doc", "UNSIGNED").Path("$.zips"),
+	}
+}
+
+type Foo30 struct {
+	ID    int32 `ddl:",auto"`
+	Name  string
+	Tags  JSON[[]int64]
+	Title JSON[string]
+}
+
+func (*Foo30) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo30) MultiValuedIndexes() []*MultiValuedIndex {
+	return []*MultiValuedIndex{
+		NewMultiValuedIndex("idx_unknown", "unknown_column", "SIGNED"),
+		NewMultiValuedIndex("idx_name", "name", "CHAR(64)"),
+		NewMultiValuedIndex("idx_tags", "tags", "UNSIGNED"),
+		NewMultiValuedIndex("idx_tags_int", "tags", "INTEGER"),
+		NewMultiValuedIndex("idx_title", "title", "CHAR(64)"),
+	}
+}
+
+type Foo31 struct {
+	ID   int32 `ddl:",auto"`
+	Code string
+}
+
+func (*Foo31) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo31) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_code", "code"),
+	}
+}
+
+func (*Foo31) TableOptions() *TableOptions {
+	return NewTableOptions().
+		Charset("latin1").
+		RowFormat("COMPRESSED").
+		KeyBlockSize(8).
+		AutoIncrement(1000).
+		StatsPersistent(false).
+		Comment("foo's table")
+}
+
+type Foo32 struct {
+	ID        int32
+	Foo31Code string
+}
+
+func (*Foo32) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo32) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_foo31_code", "foo31_code"),
+	}
+}
+
+func (*Foo32) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_foo31_code", []string{"foo31_code"}, "foo31", []string{"code"}),
+	}
+}
+
+type Foo33Status string
+
+func (Foo33Status) EnumValues() []string {
+	return []string{"active", "inactive"}
+}
+
+type Foo33Perms string
+
+func (*Foo33Perms) SetValues() []string {
+	return []string{"read", "write"}
+}
+
+type Foo33 struct {
+	ID         int32       `ddl:",auto"`
+	Status     Foo33Status `ddl:",default='active'"`
+	PrevStatus *Foo33Status
+	Perms      Foo33Perms `ddl:",default='read,write'"`
+	Kind       string     `ddl:",type=ENUM('a','b')"`
+}
+
+func (*Foo33) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo34 struct {
+	ID     int32       `ddl:",auto"`
+	Status Foo33Status `ddl:",default='deleted'"`
+	Perms  Foo33Perms  `ddl:",default='read,delete'"`
+	Kind   string      `ddl:",type=ENUM('a','a')"`
+	Flags  string      `ddl:",type=SET('a,b')"`
+}
+
+func (*Foo34) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo35 struct {
+	ID       int32 `ddl:",auto"`
+	Price    Decimal
+	Discount *Decimal `ddl:",null,precision=5,scale=2"`
+	Rate     float64  `ddl:",type=DECIMAL,precision=9,scale=6,default=0"`
+}
+
+func (*Foo35) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo36 struct {
+	ID    int32   `ddl:",auto"`
+	Price Decimal `ddl:",precision=70"`
+	Rate  Decimal `ddl:",precision=5,scale=6"`
+	Name  string  `ddl:",precision=5"`
+}
+
+func (*Foo36) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo37 struct {
+	ID        int32 `ddl:",auto"`
+	Name      string
+	CreatedAt time.Time `ddl:",auto_now_add"`
+	UpdatedAt time.Time `ddl:",auto_now"`
+	CheckedAt time.Time `ddl:",type=TIMESTAMP,auto_now"`
+}
+
+func (*Foo37) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo38 struct {
+	ID        int32     `ddl:",auto"`
+	CreatedAt time.Time `ddl:",default=CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `ddl:",size=3,default=NOW(3)"`
+	Version   int64     `ddl:",auto_now"`
+}
+
+func (*Foo38) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo39 struct {
+	ID int32 `ddl:",auto"`
+	Timestamps
+	Billing Address `ddl:",prefix=billing_"`
+}
+
+func (*Foo39) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo40 struct {
+	ID        uint64    `ddl:",auto"`
+	CreatedAt time.Time `ddl:",auto_now_add"`
+}
+
+func (*Foo40) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo41 struct {
+	ID   int32
+	Name string
+}
+
+func (*Foo41) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo41) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_name", "name(foo)"),
+	}
+}
+
+func (*Foo41) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_name", "first name"),
+	}
+}
+
+type Foo42 struct {
+	ID         int32          `ddl:",auto"`
+	Status     sql.NullString `ddl:",type=ENUM('a','b'),null"`
+	PrevStatus *string        `ddl:",type=ENUM('a','b')"`
+}
+
+func (*Foo42) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type Foo43 struct {
+	ID        int32
+	UserName  string `ddl:"user-name"`
+	FirstName string `ddl:"first name"`
+}
+
+func (*Foo43) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*Foo43) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_user_name", "user-name"),
+	}
+}
+
+func (*Foo43) UniqueIndexes() []*UniqueIndex {
+	return []*UniqueIndex{
+		NewUniqueIndex("uniq_first_name", "first name"),
+	}
+}
+
 func testMaker(t *testing.T, structs []any, ddl string) {
 	t.Helper()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -568,6 +961,161 @@ func TestMaker_Generate(t *testing.T) {
 		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
 		"SET foreign_key_checks=1;\n")
 
+	// RANGE COLUMNS partitioning
+	testMaker(t, []any{&Foo21{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo21`;\n\n"+
+		"CREATE TABLE `foo21` (\n"+
+		"    `id` BIGINT NOT NULL AUTO_INCREMENT,\n"+
+		"    `created_at` DATETIME(6) NOT NULL,\n"+
+		"    PRIMARY KEY (`id`, `created_at`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin\n"+
+		"PARTITION BY RANGE COLUMNS (`created_at`) (\n"+
+		"    PARTITION `p2022` VALUES LESS THAN ('2023-01-01 00:00:00'),\n"+
+		"    PARTITION `pmax` VALUES LESS THAN (MAXVALUE) COMMENT 'the rest'\n"+
+		");\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// LINEAR HASH partitioning
+	testMaker(t, []any{&Foo22{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo22`;\n\n"+
+		"CREATE TABLE `foo22` (\n"+
+		"    `id` BIGINT NOT NULL AUTO_INCREMENT,\n"+
+		"    `user_id` BIGINT NOT NULL,\n"+
+		"    PRIMARY KEY (`id`, `user_id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin\n"+
+		"PARTITION BY LINEAR HASH (`user_id` DIV 100) PARTITIONS 4;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// generated columns
+	testMaker(t, []any{&Foo24{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo24`;\n\n"+
+		"CREATE TABLE `foo24` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `doc` JSON NOT NULL,\n"+
+		"    `doc_id` BIGINT AS (JSON_EXTRACT(doc, '$.id')) STORED NOT NULL,\n"+
+		"    `doc_title` VARCHAR(191) AS (doc->>'$.title') VIRTUAL NULL,\n"+
+		"    INDEX `idx_doc_id` (`doc_id`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// CHECK constraints
+	testMaker(t, []any{&Foo25{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo25`;\n\n"+
+		"CREATE TABLE `foo25` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `age` INTEGER NOT NULL,\n"+
+		"    `score` INTEGER NOT NULL,\n"+
+		"    CONSTRAINT `chk_age` CHECK (`age` >= 0),\n"+
+		"    CONSTRAINT `chk_score` CHECK (`score` BETWEEN 0 AND 100) NOT ENFORCED,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// key parts
+	testMaker(t, []any{&Foo27{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo27`;\n\n"+
+		"CREATE TABLE `foo27` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `name` VARCHAR(191) NOT NULL,\n"+
+		"    `email` VARCHAR(191) NOT NULL,\n"+
+		"    `created_at` DATETIME(6) NOT NULL,\n"+
+		"    INDEX `idx_name_created_at` (`name`(32), `created_at` DESC),\n"+
+		"    UNIQUE `uniq_email` ((LOWER(`email`))),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// multi-valued indexes
+	testMaker(t, []any{&Foo29{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo29`;\n\n"+
+		"CREATE TABLE `foo29` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `tags` JSON NOT NULL,\n"+
+		"    `names` JSON NOT NULL,\n"+
+		"    `doc` JSON NOT NULL,\n"+
+		"    INDEX `idx_tags` ((CAST(`tags`->'$' AS SIGNED ARRAY))),\n"+
+		"    INDEX `idx_names` ((CAST(`names`->'$' AS CHAR(64) ARRAY))) COMMENT 'names',\n"+
+		"    INDEX `idx_doc_zips` ((CAST(`doc`->'$.zips' AS UNSIGNED ARRAY))),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// table options
+	testMaker(t, []any{&Foo31{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo31`;\n\n"+
+		"CREATE TABLE `foo31` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `code` VARCHAR(191) NOT NULL,\n"+
+		"    UNIQUE `uniq_code` (`code`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=latin1 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 AUTO_INCREMENT=1000 STATS_PERSISTENT=0 COMMENT='foo\\'s table';\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// ENUM and SET
+	testMaker(t, []any{&Foo33{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo33`;\n\n"+
+		"CREATE TABLE `foo33` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `status` ENUM('active','inactive') NOT NULL DEFAULT 'active',\n"+
+		"    `prev_status` ENUM('active','inactive') NOT NULL,\n"+
+		"    `perms` SET('read','write') NOT NULL DEFAULT 'read,write',\n"+
+		"    `kind` ENUM('a','b') NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// DECIMAL
+	testMaker(t, []any{&Foo35{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo35`;\n\n"+
+		"CREATE TABLE `foo35` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `price` DECIMAL(10,0) NOT NULL,\n"+
+		"    `discount` DECIMAL(5,2) NULL,\n"+
+		"    `rate` DECIMAL(9,6) NOT NULL DEFAULT 0,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// auto_now and auto_now_add
+	testMaker(t, []any{&Foo37{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo37`;\n\n"+
+		"CREATE TABLE `foo37` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `name` VARCHAR(191) NOT NULL,\n"+
+		"    `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n"+
+		"    `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n"+
+		"    `checked_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// embedded structs
+	testMaker(t, []any{&Foo39{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo39`;\n\n"+
+		"CREATE TABLE `foo39` (\n"+
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n"+
+		"    `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n"+
+		"    `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n"+
+		"    `billing_street` VARCHAR(191) NOT NULL,\n"+
+		"    `billing_town` VARCHAR(191) NOT NULL,\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
+	// the key parts that are exactly the names of the columns are plain columns.
+	testMaker(t, []any{&Foo43{}}, "SET foreign_key_checks=0;\n\n"+
+		"DROP TABLE IF EXISTS `foo43`;\n\n"+
+		"CREATE TABLE `foo43` (\n"+
+		"    `id` INTEGER NOT NULL,\n"+
+		"    `user-name` VARCHAR(191) NOT NULL,\n"+
+		"    `first name` VARCHAR(191) NOT NULL,\n"+
+		"    INDEX `idx_user_name` (`user-name`),\n"+
+		"    UNIQUE `uniq_first_name` (`first name`),\n"+
+		"    PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n"+
+		"SET foreign_key_checks=1;\n")
+
 	testMakerError(t, []any{&Foo11{}, &Foo12{}}, []string{
 		`duplicated name of table: "foo11"`,
 	})
@@ -601,6 +1149,413 @@ func TestMaker_Generate(t *testing.T) {
 		`table "foo18", foreign key "fk_foo19": index required on table "foo18"`,
 		`table "foo18", foreign key "fk_foo19": column "foo19_id" and referenced column "foo19"."id" type mismatch`,
 	})
+
+	testMakerError(t, []any{&Foo25{}, &Foo26{}}, []string{
+		`table "foo26": duplicated name of check constraint: "chk_age"`,
+		`table "foo26": duplicated name of check constraint: "fk_foo25"`,
+	})
+
+	testMakerError(t, []any{&Foo27{}, &Foo28{}}, []string{
+		`table "foo28", index "idx_foo27_key": column "unknown_column" not found`,
+		`table "foo28", foreign key "fk_foo27": index required on table "foo28"`,
+	})
+
+	testMakerError(t, []any{&Foo30{}}, []string{
+		`table "foo30", multi-valued index "idx_unknown": column "unknown_column" not found`,
+		`table "foo30", multi-valued index "idx_name": column "name" is not JSON`,
+		`table "foo30", multi-valued index "idx_tags": type "UNSIGNED" doesn't match the element type int64 of column "tags"`,
+		`table "foo30", multi-valued index "idx_tags_int": invalid type "INTEGER"`,
+		`table "foo30", multi-valued index "idx_title": column "title" is not an array`,
+	})
+
+	testMakerError(t, []any{&Foo31{}, &Foo32{}}, []string{
+		`table "foo32", foreign key "fk_foo31_code": column "foo31_code" and referenced column "foo31"."code" character set mismatch`,
+	})
+
+	testMakerError(t, []any{&Foo34{}}, []string{
+		`table "foo34", column "status": default value 'deleted' is not a value of ENUM`,
+		`table "foo34", column "perms": default value 'read,delete' is not a value of SET`,
+		`table "foo34", column "kind": duplicated value of ENUM: "a"`,
+		`table "foo34", column "flags": value of SET can't contain commas: "a,b"`,
+	})
+
+	testMakerError(t, []any{&Foo36{}}, []string{
+		`table "foo36", column "price": precision must be between 1 and 65, but got 70`,
+		`table "foo36", column "rate": scale 6 must not be greater than precision 5`,
+		`table "foo36", column "name": precision and scale are available only for DECIMAL`,
+	})
+
+	testMakerError(t, []any{&Foo38{}}, []string{
+		`table "foo38", column "created_at": the precision of the default value CURRENT_TIMESTAMP doesn't match the size 6`,
+		`table "foo38", column "version": auto_now and auto_now_add are available only for DATETIME and TIMESTAMP`,
+	})
+
+	testMakerError(t, []any{&Foo23{}}, []string{
+		`table "foo23", partitions: column "user_id" must be included in primary key`,
+		`table "foo23", partitions: column "user_id" must be included in unique index "uniq_name"`,
+		`table "foo23", partitions: LIST partitioning requires partition definitions`,
+	})
+
+	testMakerError(t, []any{&Foo41{}}, []string{
+		`table "foo41": invalid key part "name(foo)": myddlmaker: line 1: expected an integer, but got "foo"`,
+		`table "foo41": invalid key part "first name": myddlmaker: line 1: unexpected "name"`,
+	})
+}
+
+func TestMaker_Generate_Bootstrap(t *testing.T) {
+	tests := []struct {
+		config *Config
+		want   string
+	}{
+		{
+			config: &Config{
+				DB: &DBConfig{
+					Name:    "app",
+					Engine:  "InnoDB",
+					Charset: "utf8mb4",
+					Collate: "utf8mb4_bin",
+				},
+				Bootstrap: true,
+			},
+			want: "CREATE DATABASE IF NOT EXISTS `app` DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+				"USE `app`;\n\n" +
+				"SET foreign_key_checks=0;\n\n" +
+				"CREATE TABLE IF NOT EXISTS `foo1` (\n" +
+				"    `id` INTEGER NOT NULL,\n" +
+				"    PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+				"SET foreign_key_checks=1;\n",
+		},
+		{
+			// without the database name
+			config: &Config{
+				DB: &DBConfig{
+					Engine: "InnoDB",
+				},
+				Bootstrap:            true,
+				KeepForeignKeyChecks: true,
+			},
+			want: "CREATE TABLE IF NOT EXISTS `foo1` (\n" +
+				"    `id` INTEGER NOT NULL,\n" +
+				"    PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB;\n\n",
+		},
+		{
+			config: &Config{
+				DB: &DBConfig{
+					Engine: "InnoDB",
+				},
+				SkipDropTable: true,
+			},
+			want: "SET foreign_key_checks=0;\n\n" +
+				"CREATE TABLE `foo1` (\n" +
+				"    `id` INTEGER NOT NULL,\n" +
+				"    PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB;\n\n" +
+				"SET foreign_key_checks=1;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		m, err := New(tt.config)
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddStructs(&Foo1{})
+
+		var buf bytes.Buffer
+		if err := m.Generate(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+			t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_GeneratedColumns(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo24{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// the generated columns are not inserted nor updated, but they are selected.
+	queries := []string{
+		`"INSERT INTO ` + "`foo24` (`doc`) VALUES (?)" + `"`,
+		`"UPDATE ` + "`foo24` SET `doc` = ? WHERE `id` = ?" + `"`,
+		`"SELECT ` + "`id`, `doc`, `doc_id`, `doc_title` FROM `foo24` WHERE `id` = ?" + `"`,
+	}
+	for _, q := range queries {
+		if !strings.Contains(got, q) {
+			t.Errorf("want %s in the generated code, but not found:\n%s", q, got)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_AutoNow(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo37{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// the database sets the timestamps, but they are selected.
+	queries := []string{
+		`"INSERT INTO ` + "`foo37` (`name`) VALUES (?)" + `"`,
+		`"UPDATE ` + "`foo37` SET `name` = ? WHERE `id` = ?" + `"`,
+		`"SELECT ` + "`id`, `name`, `created_at`, `updated_at`, `checked_at` FROM `foo37` WHERE `id` = ?" + `"`,
+	}
+	for _, q := range queries {
+		if !strings.Contains(got, q) {
+			t.Errorf("want %s in the generated code, but not found:\n%s", q, got)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_NoInsertValues(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo40{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// the database generates all columns.
+	q := `"INSERT INTO ` + "`foo40` () VALUES ()" + `"`
+	if !strings.Contains(got, q) {
+		t.Errorf("want %s in the generated code, but not found:\n%s", q, got)
+	}
+}
+
+func TestMaker_GenerateGo_Embedded(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo39{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// the fields of embedded structs are addressed by their selectors.
+	fields := []string{
+		"v.Billing.Street, v.Billing.City",
+		"&v.ID, &v.Timestamps.CreatedAt, &v.Timestamps.UpdatedAt, &v.Billing.Street, &v.Billing.City",
+		"value.Billing.Street, value.Billing.City, value.ID",
+	}
+	for _, f := range fields {
+		if !strings.Contains(got, f) {
+			t.Errorf("want %s in the generated code, but not found:\n%s", f, got)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_MultiValuedIndexes(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo29{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// the lookup functions take the element type of JSON[T].
+	funcs := []string{
+		"func SelectFoo29ByIdxTags(ctx context.Context, queryer queryer, value int64) ([]*Foo29, error) {",
+		"func SelectFoo29ByIdxNames(ctx context.Context, queryer queryer, value string) ([]*Foo29, error) {",
+		"func SelectFoo29ByIdxDocZips(ctx context.Context, queryer queryer, value any) ([]*Foo29, error) {",
+		`"SELECT ` + "`id`, `tags`, `names`, `doc` FROM `foo29` WHERE ? MEMBER OF(`tags`->'$') ORDER BY `id`" + `"`,
+		`"SELECT ` + "`id`, `tags`, `names`, `doc` FROM `foo29` WHERE ? MEMBER OF(`doc`->'$.zips') ORDER BY `id`" + `"`,
+	}
+	for _, f := range funcs {
+		if !strings.Contains(got, f) {
+			t.Errorf("want %s in the generated code, but not found:\n%s", f, got)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_Enum(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo33{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// InsertFoo33 and UpdateFoo33 reject the values out of ENUM and SET.
+	want := "func validateFoo33(v *Foo33) error {\n" +
+		"\tswitch v.Status {\n" +
+		"\tcase \"active\", \"inactive\":\n" +
+		"\tdefault:\n" +
+		"\t\treturn fmt.Errorf(\"invalid value of `foo33`.`status`: %q\", v.Status)\n" +
+		"\t}\n" +
+		"\tif v.PrevStatus != nil {\n" +
+		"\t\tswitch *v.PrevStatus {\n" +
+		"\t\tcase \"active\", \"inactive\":\n" +
+		"\t\tdefault:\n" +
+		"\t\t\treturn fmt.Errorf(\"invalid value of `foo33`.`prev_status`: %q\", *v.PrevStatus)\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\tif v.Perms != \"\" {\n" +
+		"\t\tfor _, s := range strings.Split(string(v.Perms), \",\") {\n" +
+		"\t\t\tswitch s {\n" +
+		"\t\t\tcase \"read\", \"write\":\n" +
+		"\t\t\tdefault:\n" +
+		"\t\t\t\treturn fmt.Errorf(\"invalid value of `foo33`.`perms`: %q\", s)\n" +
+		"\t\t\t}\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\tswitch v.Kind {\n" +
+		"\tcase \"a\", \"b\":\n" +
+		"\tdefault:\n" +
+		"\t\treturn fmt.Errorf(\"invalid value of `foo33`.`kind`: %q\", v.Kind)\n" +
+		"\t}\n" +
+		"\treturn nil\n" +
+		"}\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("want %s in the generated code, but not found:\n%s", want, got)
+	}
+	for _, name := range []string{"InsertFoo33", "UpdateFoo33"} {
+		want := "func " + name + "(ctx context.Context, execer execer, values ...*Foo33) error {\n" +
+			"\tfor _, v := range values {\n" +
+			"\t\tif err := validateFoo33(v); err != nil {\n"
+		if !strings.Contains(got, want) {
+			t.Errorf("%s doesn't validate the values:\n%s", name, got)
+		}
+	}
+	for _, pkg := range []string{`"fmt"`, `"strings"`} {
+		if !strings.Contains(got, pkg) {
+			t.Errorf("want import %s, but not found:\n%s", pkg, got)
+		}
+	}
+}
+
+func TestMaker_GenerateGo_EnumKinds(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		PackageName: "schema",
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo42{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateGo(&buf); err != nil {
+		t.Fatalf("failed to generate go: %v", err)
+	}
+	got := buf.String()
+
+	// sql.NullString is not validated, *string is validated after the nil check.
+	want := "func validateFoo42(v *Foo42) error {\n" +
+		"\tif v.PrevStatus != nil {\n" +
+		"\t\tswitch *v.PrevStatus {\n" +
+		"\t\tcase \"a\", \"b\":\n" +
+		"\t\tdefault:\n" +
+		"\t\t\treturn fmt.Errorf(\"invalid value of `foo42`.`prev_status`: %q\", *v.PrevStatus)\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn nil\n" +
+		"}\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("want %s in the generated code, but not found:\n%s", want, got)
+	}
+
+	// the generated code compiles.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/schema\n\ngo 1.18\n",
+		"schema.go": "package schema\n\n" +
+			"import \"database/sql\"\n\n" +
+			"type Foo42 struct {\n" +
+			"\tID         int32\n" +
+			"\tStatus     sql.NullString\n" +
+			"\tPrevStatus *string\n" +
+			"}\n",
+		"schema_gen.go": got,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, goTool(), "build", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("failed to build the generated code: %v, output:\n%s", err, out)
+	}
 }
 
 func TestMaker_GenerateGo(t *testing.T) {
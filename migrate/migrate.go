@@ -0,0 +1,501 @@
+// Package migrate applies versioned migration files to MySQL databases.
+//
+// The migration files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+// The applied versions are recorded in the schema_migrations table,
+// and GET_LOCK prevents two processes from migrating the same database concurrently.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTable       = "schema_migrations"
+	defaultLockName    = "myddlmaker_migrate"
+	defaultLockTimeout = time.Minute
+)
+
+// ErrLocked is returned when another process holds the migration lock.
+var ErrLocked = errors.New("migrate: failed to get the migration lock")
+
+// ErrNoChange is returned by Down when there are no applied migrations.
+var ErrNoChange = errors.New("migrate: no change")
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// DB is a database handle, e.g. *sql.DB, *sql.Conn and *sql.Tx.
+type DB interface {
+	execer
+	queryer
+}
+
+// connector is implemented by *sql.DB.
+type connector interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// Config is the configuration of Migrator.
+type Config struct {
+	// Table is the name of the table that records the applied versions.
+	// The default is "schema_migrations".
+	Table string
+
+	// LockName is the name of the lock that is passed to GET_LOCK.
+	// The default is "myddlmaker_migrate".
+	LockName string
+
+	// LockTimeout is the time to wait for the lock.
+	// The default is one minute. Negative values mean waiting forever.
+	LockTimeout time.Duration
+}
+
+// Migration is a versioned change of the schema.
+type Migration struct {
+	Version int64
+	Name    string
+
+	// Up is the SQL statements that apply the migration.
+	Up string
+
+	// Down is the SQL statements that revert the migration.
+	// It is empty if the migration is irreversible.
+	Down string
+}
+
+// Status is the status of a migration.
+type Status struct {
+	Version int64
+	Name    string
+
+	// Applied reports whether the migration has been applied.
+	Applied bool
+
+	// Dirty reports whether the migration has failed in the middle.
+	// The database needs to be fixed by hand, and then the row needs to be removed
+	// from the schema_migrations table.
+	Dirty bool
+
+	// AppliedAt is the time in UTC when the migration was applied.
+	AppliedAt time.Time
+}
+
+// Migrator applies migrations.
+type Migrator struct {
+	config     *Config
+	migrations []*Migration
+}
+
+// New returns a new Migrator that applies the migration files in fsys.
+func New(fsys fs.FS, config *Config) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.Table == "" {
+		cfg.Table = defaultTable
+	}
+	if cfg.LockName == "" {
+		cfg.LockName = defaultLockName
+	}
+	if cfg.LockTimeout == 0 {
+		cfg.LockTimeout = defaultLockTimeout
+	}
+
+	return &Migrator{
+		config:     &cfg,
+		migrations: migrations,
+	}, nil
+}
+
+var migrationFileName = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+
+// Load reads the migration files in the root directory of fsys.
+// Files that don't match <version>_<name>.(up|down).sql are ignored.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to read the migration directory: %w", err)
+	}
+
+	migrations := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := migrationFileName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version %q: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to read %q: %w", entry.Name(), err)
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{
+				Version: version,
+				Name:    matches[2],
+			}
+			migrations[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migrate: duplicated version: %d", version)
+		}
+		switch matches[3] {
+		case "up":
+			m.Up = string(data)
+		case "down":
+			m.Down = string(data)
+		}
+	}
+
+	ret := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d: up migration is missing", m.Version)
+		}
+		ret = append(ret, m)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Version < ret[j].Version
+	})
+	return ret, nil
+}
+
+// Up applies all pending migrations in the order of their versions.
+func (m *Migrator) Up(ctx context.Context, db DB) error {
+	return m.withLock(ctx, db, func(db DB) error {
+		applied, err := m.applied(ctx, db)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.up(ctx, db, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) up(ctx context.Context, db DB, migration *Migration) error {
+	table := quote(m.config.Table)
+	_, err := db.ExecContext(ctx, "INSERT INTO "+table+" (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 1, UTC_TIMESTAMP(6))", migration.Version, migration.Name)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to record version %d: %w", migration.Version, err)
+	}
+	if err := execStatements(ctx, db, migration.Up); err != nil {
+		return fmt.Errorf("migrate: version %d: %w", migration.Version, err)
+	}
+	_, err = db.ExecContext(ctx, "UPDATE "+table+" SET `dirty` = 0 WHERE `version` = ?", migration.Version)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to record version %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// Down reverts the latest applied migration.
+// It returns ErrNoChange if there are no applied migrations.
+func (m *Migrator) Down(ctx context.Context, db DB) error {
+	return m.withLock(ctx, db, func(db DB) error {
+		applied, err := m.applied(ctx, db)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		var latest *Status
+		for _, s := range applied {
+			if latest == nil || latest.Version < s.Version {
+				latest = s
+			}
+		}
+		if latest == nil {
+			return ErrNoChange
+		}
+
+		var migration *Migration
+		for _, mi := range m.migrations {
+			if mi.Version == latest.Version {
+				migration = mi
+				break
+			}
+		}
+		if migration == nil {
+			return fmt.Errorf("migrate: version %d: migration file is missing", latest.Version)
+		}
+		if migration.Down == "" {
+			return fmt.Errorf("migrate: version %d: down migration is missing", latest.Version)
+		}
+		return m.down(ctx, db, migration)
+	})
+}
+
+func (m *Migrator) down(ctx context.Context, db DB, migration *Migration) error {
+	table := quote(m.config.Table)
+	_, err := db.ExecContext(ctx, "UPDATE "+table+" SET `dirty` = 1 WHERE `version` = ?", migration.Version)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to record version %d: %w", migration.Version, err)
+	}
+	if err := execStatements(ctx, db, migration.Down); err != nil {
+		return fmt.Errorf("migrate: version %d: %w", migration.Version, err)
+	}
+	_, err = db.ExecContext(ctx, "DELETE FROM "+table+" WHERE `version` = ?", migration.Version)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to record version %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// Status returns the statuses of the migrations in the order of their versions.
+// The versions that are applied but missing in the migration files are also included.
+// It doesn't take the migration lock, so it reports the statuses while another process is migrating.
+func (m *Migrator) Status(ctx context.Context, db DB) ([]*Status, error) {
+	exists, err := m.tableExists(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	applied := map[int64]*Status{}
+	if exists {
+		applied, err = m.applied(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ret []*Status
+	for _, migration := range m.migrations {
+		if s, ok := applied[migration.Version]; ok {
+			ret = append(ret, s)
+			delete(applied, migration.Version)
+			continue
+		}
+		ret = append(ret, &Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		})
+	}
+	for _, s := range applied {
+		ret = append(ret, s)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Version < ret[j].Version
+	})
+	return ret, nil
+}
+
+// tableExists reports whether the table that records the applied versions exists.
+// No migrations are applied if it doesn't exist.
+func (m *Migrator) tableExists(ctx context.Context, db DB) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM `information_schema`.`TABLES` WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?", m.config.Table).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("migrate: failed to query %s: %w", m.config.Table, err)
+	}
+	return n > 0, nil
+}
+
+// withLock calls f with holding the migration lock.
+// GET_LOCK is bound to the session, so f is called with a dedicated connection if db is *sql.DB.
+func (m *Migrator) withLock(ctx context.Context, db DB, f func(db DB) error) (err error) {
+	if c, ok := db.(connector); ok {
+		conn, err := c.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate: failed to get a connection: %w", err)
+		}
+		defer conn.Close()
+		db = conn
+	}
+
+	timeout := -1
+	if m.config.LockTimeout > 0 {
+		timeout = int((m.config.LockTimeout + time.Second - 1) / time.Second)
+	}
+	var locked sql.NullInt64
+	if err := db.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", m.config.LockName, timeout).Scan(&locked); err != nil {
+		return fmt.Errorf("migrate: failed to get the migration lock: %w", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return ErrLocked
+	}
+	defer func() {
+		// release the lock even if ctx is canceled.
+		var released sql.NullInt64
+		err0 := db.QueryRowContext(context.Background(), "SELECT RELEASE_LOCK(?)", m.config.LockName).Scan(&released)
+		if err0 != nil && err == nil {
+			err = fmt.Errorf("migrate: failed to release the migration lock: %w", err0)
+		}
+	}()
+
+	if err := m.createTable(ctx, db); err != nil {
+		return err
+	}
+	return f(db)
+}
+
+func (m *Migrator) createTable(ctx context.Context, db DB) error {
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+quote(m.config.Table)+" (\n"+
+		"    `version` BIGINT NOT NULL,\n"+
+		"    `name` VARCHAR(255) NOT NULL,\n"+
+		"    `dirty` TINYINT(1) NOT NULL,\n"+
+		"    `applied_at` DATETIME(6) NOT NULL,\n"+
+		"    PRIMARY KEY (`version`)\n"+
+		")")
+	if err != nil {
+		return fmt.Errorf("migrate: failed to create %s: %w", m.config.Table, err)
+	}
+	return nil
+}
+
+// applied returns the applied migrations.
+func (m *Migrator) applied(ctx context.Context, db DB) (map[int64]*Status, error) {
+	rows, err := db.QueryContext(ctx, "SELECT `version`, `name`, `dirty`, DATE_FORMAT(`applied_at`, '%Y-%m-%d %H:%i:%s.%f') FROM "+quote(m.config.Table))
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to query applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	ret := map[int64]*Status{}
+	for rows.Next() {
+		var s Status
+		var appliedAt string
+		if err := rows.Scan(&s.Version, &s.Name, &s.Dirty, &appliedAt); err != nil {
+			return nil, fmt.Errorf("migrate: failed to scan applied versions: %w", err)
+		}
+		s.Applied = true
+		s.AppliedAt, err = time.Parse("2006-01-02 15:04:05.999999", appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to parse applied_at: %w", err)
+		}
+		ret[s.Version] = &s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("migrate: failed to query applied versions: %w", err)
+	}
+	return ret, nil
+}
+
+func checkDirty(applied map[int64]*Status) error {
+	for _, s := range applied {
+		if s.Dirty {
+			return fmt.Errorf("migrate: version %d is dirty: fix the database and remove it from the table", s.Version)
+		}
+	}
+	return nil
+}
+
+func execStatements(ctx context.Context, db execer, sql string) error {
+	for _, stmt := range splitStatements(sql) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to execute %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// splitStatements splits sql into statements separated by semicolons.
+// Semicolons in string literals, quoted identifiers and comments are ignored.
+// Statements that contain only comments are skipped, except for executable comments /*! ... */.
+func splitStatements(sql string) []string {
+	var ret []string
+	start := 0
+	hasCode := false
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == ';':
+			if hasCode {
+				ret = append(ret, strings.TrimSpace(sql[start:i]))
+			}
+			start = i + 1
+			hasCode = false
+		case ch == '\'' || ch == '"' || ch == '`':
+			hasCode = true
+			i++
+			for i < len(sql) {
+				if sql[i] == '\\' && ch != '`' {
+					i += 2
+					continue
+				}
+				if sql[i] == ch {
+					if i+1 < len(sql) && sql[i+1] == ch {
+						// escaped quote, e.g. 'it''s'
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+		case ch == '#' || isDashComment(sql[i:]):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if strings.HasPrefix(sql[i:], "/*!") {
+				hasCode = true
+			}
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+		default:
+			hasCode = true
+		}
+	}
+	if hasCode {
+		ret = append(ret, strings.TrimSpace(sql[start:]))
+	}
+	return ret
+}
+
+// isDashComment reports whether s starts with a comment "-- ".
+// MySQL requires the second dash to be followed by a whitespace or a control character.
+func isDashComment(s string) bool {
+	return len(s) >= 2 && s[0] == '-' && s[1] == '-' && (len(s) == 2 || s[2] <= ' ')
+}
+
+// quote quotes s with `s`.
+func quote(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
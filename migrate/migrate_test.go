@@ -0,0 +1,266 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"2_add_email.up.sql":     {Data: []byte("ALTER TABLE `user` ADD COLUMN `email` VARCHAR(191) NOT NULL;")},
+		"2_add_email.down.sql":   {Data: []byte("ALTER TABLE `user` DROP COLUMN `email`;")},
+		"1_create_user.up.sql":   {Data: []byte("CREATE TABLE `user` (`id` INTEGER NOT NULL, PRIMARY KEY (`id`));")},
+		"1_create_user.down.sql": {Data: []byte("DROP TABLE `user`;")},
+		"3_irreversible.up.sql":  {Data: []byte("DROP TABLE `foo`;")},
+		"README.md":              {Data: []byte("# migrations")},
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Migration{
+		{
+			Version: 1,
+			Name:    "create_user",
+			Up:      "CREATE TABLE `user` (`id` INTEGER NOT NULL, PRIMARY KEY (`id`));",
+			Down:    "DROP TABLE `user`;",
+		},
+		{
+			Version: 2,
+			Name:    "add_email",
+			Up:      "ALTER TABLE `user` ADD COLUMN `email` VARCHAR(191) NOT NULL;",
+			Down:    "ALTER TABLE `user` DROP COLUMN `email`;",
+		},
+		{
+			Version: 3,
+			Name:    "irreversible",
+			Up:      "DROP TABLE `foo`;",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("migrations are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestLoad_Error(t *testing.T) {
+	tests := []struct {
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			fsys: fstest.MapFS{
+				"1_foo.up.sql": {Data: []byte("SELECT 1;")},
+				"1_bar.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			want: "migrate: duplicated version: 1",
+		},
+		{
+			fsys: fstest.MapFS{
+				"1_foo.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			want: "migrate: version 1: up migration is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := Load(tt.fsys)
+		if err == nil {
+			t.Errorf("want some error, but not")
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("unexpected error: got %q, want %q", err.Error(), tt.want)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{
+			sql:  "",
+			want: nil,
+		},
+		{
+			sql:  "SELECT 1;\nSELECT 2",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			sql:  "-- comment;\n# comment;\n/* comment; */\nSELECT 1;\n\n",
+			want: []string{"-- comment;\n# comment;\n/* comment; */\nSELECT 1"},
+		},
+		{
+			sql:  "-- only comments;\n",
+			want: nil,
+		},
+		{
+			sql:  "SELECT 'a;b', \"c;d\", `e;f`, 'it''s;', 'it\\'s;';",
+			want: []string{"SELECT 'a;b', \"c;d\", `e;f`, 'it''s;', 'it\\'s;'"},
+		},
+		{
+			sql:  "/*!40101 SET NAMES utf8mb4 */;\nSELECT 1--1;",
+			want: []string{"/*!40101 SET NAMES utf8mb4 */", "SELECT 1--1"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := splitStatements(tt.sql)
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("%q: statements are not match (-want/+got):\n%s", tt.sql, diff)
+		}
+	}
+}
+
+func setupDatabase(ctx context.Context, t testing.TB) (db *sql.DB, ok bool) {
+	user := os.Getenv("MYSQL_TEST_USER")
+	pass := os.Getenv("MYSQL_TEST_PASS")
+	addr := os.Getenv("MYSQL_TEST_ADDR")
+	if user == "" || pass == "" || addr == "" {
+		return nil, false
+	}
+
+	// connect to the server
+	cfg := mysql.NewConfig()
+	cfg.User = user
+	cfg.Passwd = pass
+	cfg.Addr = addr
+	db0, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db0.Close()
+
+	// create a new database
+	var buf [4]byte
+	_, err = rand.Read(buf[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbName := fmt.Sprintf("myddlmaker_%x", buf[:])
+	_, err = db0.ExecContext(ctx, "CREATE DATABASE "+dbName)
+	if err != nil {
+		t.Fatalf("failed to create database %q: %v", dbName, err)
+	}
+
+	cfg.DBName = dbName
+	db, err = sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() {
+		db0.ExecContext(ctx, "DROP DATABASE "+dbName)
+		db.Close()
+	})
+	return db, true
+}
+
+func TestMigrator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	db, ok := setupDatabase(ctx, t)
+	if !ok {
+		t.Skip("MYSQL_TEST_USER, MYSQL_TEST_PASS and MYSQL_TEST_ADDR are not set")
+	}
+
+	m, err := New(fstest.MapFS{
+		"1_create_user.up.sql":   {Data: []byte("CREATE TABLE `user` (`id` INTEGER NOT NULL, PRIMARY KEY (`id`));\nINSERT INTO `user` VALUES (1);")},
+		"1_create_user.down.sql": {Data: []byte("DROP TABLE `user`;")},
+		"2_add_email.up.sql":     {Data: []byte("ALTER TABLE `user` ADD COLUMN `email` VARCHAR(191) NOT NULL DEFAULT '';")},
+		"2_add_email.down.sql":   {Data: []byte("ALTER TABLE `user` DROP COLUMN `email`;")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	// Up is idempotent.
+	if err := m.Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT `email` FROM `user`"); err != nil {
+		t.Errorf("the migrations are not applied: %v", err)
+	}
+
+	status, err := m.Status(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 2 || !status[0].Applied || !status[1].Applied {
+		t.Errorf("unexpected status: %v", status)
+	}
+
+	if err := m.Down(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	status, err = m.Status(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 2 || !status[0].Applied || status[1].Applied {
+		t.Errorf("unexpected status: %v", status)
+	}
+
+	if err := m.Down(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Down(ctx, db); !errors.Is(err, ErrNoChange) {
+		t.Errorf("want ErrNoChange, got %v", err)
+	}
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	db, ok := setupDatabase(ctx, t)
+	if !ok {
+		t.Skip("MYSQL_TEST_USER, MYSQL_TEST_PASS and MYSQL_TEST_ADDR are not set")
+	}
+
+	m, err := New(fstest.MapFS{}, &Config{
+		LockName:    "myddlmaker_migrate_test",
+		LockTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another process holds the lock.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "DO GET_LOCK('myddlmaker_migrate_test', 0)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Up(ctx, db); !errors.Is(err, ErrLocked) {
+		t.Errorf("want ErrLocked, got %v", err)
+	}
+	// Status doesn't need the lock.
+	if _, err := m.Status(ctx, db); err != nil {
+		t.Errorf("failed to get the status: %v", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "DO RELEASE_LOCK('myddlmaker_migrate_test')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx, db); err != nil {
+		t.Error(err)
+	}
+}
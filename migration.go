@@ -0,0 +1,209 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MigrationFormat is a naming convention of migration files.
+type MigrationFormat string
+
+const (
+	// MigrationFormatGolangMigrate is the format of golang-migrate.
+	// The files are named <version>_<name>.up.sql and <version>_<name>.down.sql.
+	// https://github.com/golang-migrate/migrate/blob/master/MIGRATIONS.md
+	MigrationFormatGolangMigrate MigrationFormat = "golang-migrate"
+
+	// MigrationFormatFlyway is the format of Flyway.
+	// The files are named V<version>__<name>.sql and U<version>__<name>.sql.
+	// https://documentation.red-gate.com/fd/migrations-184127470.html
+	MigrationFormatFlyway MigrationFormat = "flyway"
+)
+
+// migrationVersionLayout is the layout of the versions of migration files.
+const migrationVersionLayout = "20060102150405"
+
+// GenerateMigrationFiles writes a new pair of up and down migration files into Config.MigrationDir.
+// The previous schema is read from Config.OutFilePath, and then Config.OutFilePath is
+// updated to the current schema for the next migration.
+// If Config.OutDir is set, the baseline is split into the files in Config.OutDir as GenerateFile does.
+// If the schema is not changed, no migration files are written.
+func (m *Maker) GenerateMigrationFiles() error {
+	if m.config.MigrationDir == "" {
+		return errors.New("myddlmaker: MigrationDir is empty")
+	}
+	if err := m.parse(); err != nil {
+		return err
+	}
+
+	from, err := m.readBaseline()
+	if err != nil {
+		return err
+	}
+
+	cur := m.currentSchema()
+	r, err := findRenames(from.tables, cur.tables)
+	if err != nil {
+		return err
+	}
+	upChanges := m.diff(from, cur, r)
+	if len(upChanges) == 0 {
+		return nil
+	}
+	if err := m.checkDestructive(upChanges); err != nil {
+		return err
+	}
+	downChanges := m.diff(cur, from, r.inverse())
+
+	var up, down bytes.Buffer
+	m.writeChanges(&up, upChanges)
+	m.writeChanges(&down, downChanges)
+
+	// build the baseline of the next migration before writing any file,
+	// so that a failure doesn't leave the migration files without the baseline.
+	var files map[string][]byte
+	var baseline []byte
+	if m.config.OutDir != "" {
+		files, baseline, err = m.splitFiles()
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to generate ddl: %w", err)
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := m.Generate(&buf); err != nil {
+			return fmt.Errorf("myddlmaker: failed to generate ddl: %w", err)
+		}
+		baseline = buf.Bytes()
+	}
+
+	if err := os.MkdirAll(m.config.MigrationDir, 0o755); err != nil {
+		return fmt.Errorf("myddlmaker: failed to create %q: %w", m.config.MigrationDir, err)
+	}
+	version, err := m.migrationVersion()
+	if err != nil {
+		return err
+	}
+	upName, downName := m.migrationFileNames(version)
+	if err := writeNewFile(filepath.Join(m.config.MigrationDir, upName), up.Bytes()); err != nil {
+		return err
+	}
+	if err := writeNewFile(filepath.Join(m.config.MigrationDir, downName), down.Bytes()); err != nil {
+		return err
+	}
+
+	// save the current schema as the baseline of the next migration.
+	if m.config.OutDir != "" {
+		return m.writeSplitFiles(files, baseline)
+	}
+	if err := os.WriteFile(m.config.OutFilePath, baseline, 0o644); err != nil {
+		return fmt.Errorf("myddlmaker: failed to write %q: %w", m.config.OutFilePath, err)
+	}
+	return nil
+}
+
+// readBaseline reads the schema from Config.OutFilePath.
+// The files that Config.OutFilePath sources, e.g. the files in Config.OutDir, are read too.
+// It returns an empty schema if Config.OutFilePath doesn't exist.
+func (m *Maker) readBaseline() (*Schema, error) {
+	data, err := os.ReadFile(m.config.OutFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Schema{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("myddlmaker: failed to read %q: %w", m.config.OutFilePath, err)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "SOURCE ") {
+			buf.WriteString(line)
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "SOURCE "), ";"))
+		src, err := os.ReadFile(filepath.FromSlash(name))
+		if err != nil {
+			return nil, fmt.Errorf("myddlmaker: failed to read %q: %w", name, err)
+		}
+		buf.Write(src)
+		buf.WriteString(";\n")
+	}
+
+	s, err := ParseSQL(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("myddlmaker: failed to parse %q: %w", m.config.OutFilePath, err)
+	}
+	return s, nil
+}
+
+// migrationVersion returns the version of the new migration files.
+// It is the current time, but the resolution of the versions is one second,
+// so it is bumped to be later than the versions of the existing migration files.
+func (m *Maker) migrationVersion() (string, error) {
+	now := time.Now
+	if m.now != nil {
+		now = m.now
+	}
+	version := now().UTC().Truncate(time.Second)
+
+	entries, err := os.ReadDir(m.config.MigrationDir)
+	if err != nil {
+		return "", fmt.Errorf("myddlmaker: failed to read %q: %w", m.config.MigrationDir, err)
+	}
+	for _, entry := range entries {
+		v, ok := migrationFileVersion(entry.Name())
+		if ok && !version.After(v) {
+			version = v.Add(time.Second)
+		}
+	}
+	return version.Format(migrationVersionLayout), nil
+}
+
+// migrationFileVersion parses the version of the migration file named name.
+// It accepts both of MigrationFormatGolangMigrate and MigrationFormatFlyway.
+func migrationFileVersion(name string) (time.Time, bool) {
+	if strings.HasPrefix(name, "V") || strings.HasPrefix(name, "U") {
+		name = name[1:]
+	}
+	if len(name) < len(migrationVersionLayout) {
+		return time.Time{}, false
+	}
+	v, err := time.Parse(migrationVersionLayout, name[:len(migrationVersionLayout)])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return v, true
+}
+
+// migrationFileNames returns the names of the up and down migration files of the version.
+func (m *Maker) migrationFileNames(version string) (up, down string) {
+	name := m.config.MigrationName
+
+	switch m.config.MigrationFormat {
+	case MigrationFormatFlyway:
+		return "V" + version + "__" + name + ".sql", "U" + version + "__" + name + ".sql"
+	default:
+		return version + "_" + name + ".up.sql", version + "_" + name + ".down.sql"
+	}
+}
+
+// writeNewFile writes data to the file named name.
+// It never overwrites the existing files, because migration files must be immutable once they are applied.
+func writeNewFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to open %q: %w", name, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("myddlmaker: failed to write %q: %w", name, err)
+	}
+	return f.Close()
+}
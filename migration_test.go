@@ -0,0 +1,215 @@
+package myddlmaker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testGenerateMigrationFiles(t *testing.T, config *Config, now time.Time, structs ...any) {
+	t.Helper()
+
+	m, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.now = func() time.Time { return now }
+	m.AddStructs(structs...)
+	if err := m.GenerateFile(); err != nil {
+		t.Fatalf("failed to generate migration files: %v", err)
+	}
+}
+
+func readMigrationDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret[entry.Name()] = string(data)
+	}
+	return ret
+}
+
+func TestMaker_GenerateMigrationFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutFilePath:  filepath.Join(dir, "schema.sql"),
+		MigrationDir: filepath.Join(dir, "migrations"),
+	}
+
+	t1 := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t1, &DiffUser1{})
+
+	t2 := time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t2, &DiffUser2{})
+
+	// no changes, no migration files.
+	t3 := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t3, &DiffUser2{})
+
+	want := map[string]string{
+		"20221001120000_update_schema.up.sql": "CREATE TABLE `user` (\n" +
+			"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+			"    `name` VARCHAR(191) NOT NULL,\n" +
+			"    `age` INTEGER NOT NULL,\n" +
+			"    INDEX `idx_age` (`age`),\n" +
+			"    PRIMARY KEY (`id`)\n" +
+			");\n",
+		"20221001120000_update_schema.down.sql": "DROP TABLE `user`;\n",
+		"20221002120000_update_schema.up.sql": "ALTER TABLE `user` DROP INDEX `idx_age`;\n" +
+			"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NULL;\n" +
+			"ALTER TABLE `user` ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`;\n" +
+			"ALTER TABLE `user` ADD UNIQUE `uniq_email` (`email`);\n",
+		"20221002120000_update_schema.down.sql": "ALTER TABLE `user` DROP INDEX `uniq_email`;\n" +
+			"ALTER TABLE `user` DROP COLUMN `email`;\n" +
+			"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NOT NULL;\n" +
+			"ALTER TABLE `user` ADD INDEX `idx_age` (`age`);\n",
+	}
+	got := readMigrationDir(t, config.MigrationDir)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("migration files are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateMigrationFiles_Flyway(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutFilePath:     filepath.Join(dir, "schema.sql"),
+		MigrationDir:    filepath.Join(dir, "sql"),
+		MigrationFormat: MigrationFormatFlyway,
+		MigrationName:   "create_user",
+	}
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, now, &DiffUser1{})
+
+	got := readMigrationDir(t, config.MigrationDir)
+	names := make([]string, 0, len(got))
+	for name := range got {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"U20221001120000__create_user.sql",
+		"V20221001120000__create_user.sql",
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("migration files are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateMigrationFiles_OutDir(t *testing.T) {
+	dir := t.TempDir()
+
+	// the schema that GenerateFile splits into OutDir is the baseline.
+	config := &Config{
+		OutFilePath: filepath.Join(dir, "schema.sql"),
+		OutDir:      filepath.Join(dir, "schema"),
+	}
+	t1 := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t1, &DiffUser1{})
+
+	config.MigrationDir = filepath.Join(dir, "migrations")
+	t2 := time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t2, &DiffUser2{})
+
+	// no changes, no migration files.
+	t3 := time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t3, &DiffUser2{})
+
+	want := map[string]string{
+		"20221002120000_update_schema.up.sql": "ALTER TABLE `user` DROP INDEX `idx_age`;\n" +
+			"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NULL;\n" +
+			"ALTER TABLE `user` ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`;\n" +
+			"ALTER TABLE `user` ADD UNIQUE `uniq_email` (`email`);\n",
+		"20221002120000_update_schema.down.sql": "ALTER TABLE `user` DROP INDEX `uniq_email`;\n" +
+			"ALTER TABLE `user` DROP COLUMN `email`;\n" +
+			"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NOT NULL;\n" +
+			"ALTER TABLE `user` ADD INDEX `idx_age` (`age`);\n",
+	}
+	got := readMigrationDir(t, config.MigrationDir)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("migration files are not match (-want/+got):\n%s", diff)
+	}
+
+	// the index file keeps sourcing the table files.
+	index, err := os.ReadFile(config.OutFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SOURCE " + filepath.ToSlash(filepath.Join(dir, "schema", "user.sql")) + ";\n"; !strings.Contains(string(index), want) {
+		t.Errorf("want %q in the index file, got:\n%s", want, index)
+	}
+}
+
+func TestMaker_GenerateMigrationFiles_SameVersion(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutFilePath:  filepath.Join(dir, "schema.sql"),
+		MigrationDir: filepath.Join(dir, "migrations"),
+	}
+
+	// the versions collide in the one-second resolution.
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, now, &DiffUser1{})
+	testGenerateMigrationFiles(t, config, now.Add(500*time.Millisecond), &DiffUser2{})
+
+	got := readMigrationDir(t, config.MigrationDir)
+	names := make([]string, 0, len(got))
+	for name := range got {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"20221001120000_update_schema.down.sql",
+		"20221001120000_update_schema.up.sql",
+		"20221001120001_update_schema.down.sql",
+		"20221001120001_update_schema.up.sql",
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("migration files are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateMigrationFiles_CyclicForeignKeys(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutFilePath:          filepath.Join(dir, "schema.sql"),
+		MigrationDir:         filepath.Join(dir, "migrations"),
+		KeepForeignKeyChecks: true,
+	}
+
+	t1 := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t1, &OrderEntry{}, &OrderUser{}, &OrderTeam{})
+
+	// the baseline has the constraints with cyclic references, so no changes.
+	t2 := time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	testGenerateMigrationFiles(t, config, t2, &OrderEntry{}, &OrderUser{}, &OrderTeam{})
+
+	got := readMigrationDir(t, config.MigrationDir)
+	names := make([]string, 0, len(got))
+	for name := range got {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"20221001120000_update_schema.down.sql",
+		"20221001120000_update_schema.up.sql",
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("migration files are not match (-want/+got):\n%s", diff)
+	}
+}
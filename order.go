@@ -0,0 +1,137 @@
+package myddlmaker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// deferredForeignKey is a foreign key constraint that is added after all tables are created,
+// because it is a part of cyclic references.
+type deferredForeignKey struct {
+	table string
+	fk    *ForeignKey
+}
+
+// sortTables sorts the tables in the order of the foreign key constraints.
+// The referenced tables come before the referencing tables,
+// and the tables that don't depend on each other keep the original order.
+// If the constraints have cycles, the constraints that close the cycles are deferred.
+func sortTables(tables []*table) ([]*table, []deferredForeignKey) {
+	tableMap := make(map[string]*table, len(tables))
+	for _, table := range tables {
+		tableMap[table.name] = table
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(tables))
+	sorted := make([]*table, 0, len(tables))
+	var deferred []deferredForeignKey
+
+	var visit func(table *table)
+	visit = func(table *table) {
+		state[table.name] = visiting
+		var fks []*ForeignKey
+		for _, fk := range table.foreignKeys {
+			switch {
+			case fk.table == table.name:
+				// self-references don't need the order.
+			case state[fk.table] == visiting:
+				// the constraint closes a cycle.
+				deferred = append(deferred, deferredForeignKey{table: table.name, fk: fk})
+				continue
+			case state[fk.table] == unvisited && tableMap[fk.table] != nil:
+				visit(tableMap[fk.table])
+			}
+			fks = append(fks, fk)
+		}
+		state[table.name] = visited
+
+		if len(fks) != len(table.foreignKeys) {
+			tmp := *table // shallow copy
+			tmp.foreignKeys = fks
+			table = &tmp
+		}
+		sorted = append(sorted, table)
+	}
+	for _, table := range tables {
+		if state[table.name] == unvisited {
+			visit(table)
+		}
+	}
+	return sorted, deferred
+}
+
+// orderedTables returns the tables in the order of the foreign key constraints and the deferred constraints.
+// If KeepForeignKeyChecks is disabled, the constraints are not deferred,
+// because foreign_key_checks is disabled while the tables are created.
+func (m *Maker) orderedTables() ([]*table, []deferredForeignKey, error) {
+	sorted, deferred := sortTables(m.tables)
+	if !m.config.KeepForeignKeyChecks {
+		tableMap := make(map[string]*table, len(m.tables))
+		for _, table := range m.tables {
+			tableMap[table.name] = table
+		}
+		for i, table := range sorted {
+			sorted[i] = tableMap[table.name]
+		}
+		return sorted, nil, nil
+	}
+	if m.config.Bootstrap && len(deferred) > 0 {
+		// ALTER TABLE ADD CONSTRAINT fails if the constraint already exists.
+		return nil, nil, fmt.Errorf("myddlmaker: foreign key constraint %q of table %q has cyclic references, it is not supported in Bootstrap mode", deferred[0].fk.name, deferred[0].table)
+	}
+	return sorted, deferred, nil
+}
+
+// generateOrdered writes the DDL that doesn't need to disable foreign_key_checks.
+// It drops the referencing tables first, and creates the referenced tables first.
+func (m *Maker) generateOrdered(w io.Writer) error {
+	sorted, deferred, err := m.orderedTables()
+	if err != nil {
+		return err
+	}
+	if !m.config.SkipDropTable {
+		m.generateDropTables(w, sorted)
+	}
+	for _, table := range sorted {
+		m.generateCreateTable(w, table, m.config.Bootstrap)
+		io.WriteString(w, ";\n\n")
+	}
+	m.generateDeferredForeignKeys(w, deferred)
+	return nil
+}
+
+// generateDropTables writes the DROP TABLE statement that drops the referencing tables first.
+// The tables that reference each other are dropped at once.
+func (m *Maker) generateDropTables(w io.Writer, sorted []*table) {
+	if len(sorted) == 0 {
+		return
+	}
+	names := make([]string, 0, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		names = append(names, quote(sorted[i].name))
+	}
+	io.WriteString(w, "DROP TABLE IF EXISTS ")
+	io.WriteString(w, strings.Join(names, ", "))
+	io.WriteString(w, ";\n\n")
+}
+
+// generateDeferredForeignKeys writes the constraints that sortTables deferred.
+// They are ALTER TABLE ... ADD CONSTRAINT statements, so that ParseSQL can read them back.
+func (m *Maker) generateDeferredForeignKeys(w io.Writer, deferred []deferredForeignKey) {
+	if len(deferred) > 0 {
+		io.WriteString(w, "-- the following constraints have cyclic references, they are added after the tables are created.\n")
+	}
+	for _, d := range deferred {
+		io.WriteString(w, "ALTER TABLE ")
+		io.WriteString(w, quote(d.table))
+		io.WriteString(w, " ADD ")
+		m.generateForeignKeyDefinition(w, d.fk)
+		io.WriteString(w, ";\n\n")
+	}
+}
@@ -0,0 +1,227 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type OrderEntry struct {
+	ID     int32 `ddl:",auto"`
+	UserID int32
+}
+
+func (*OrderEntry) Table() string {
+	return "entry"
+}
+
+func (*OrderEntry) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OrderEntry) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_user_id", "user_id"),
+	}
+}
+
+func (*OrderEntry) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_entry_user", []string{"user_id"}, "user", []string{"id"}),
+	}
+}
+
+type OrderUser struct {
+	ID     int32 `ddl:",auto"`
+	TeamID int32
+}
+
+func (*OrderUser) Table() string {
+	return "user"
+}
+
+func (*OrderUser) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OrderUser) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_team_id", "team_id"),
+	}
+}
+
+func (*OrderUser) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_user_team", []string{"team_id"}, "team", []string{"id"}),
+	}
+}
+
+type OrderTeam struct {
+	ID      int32 `ddl:",auto"`
+	OwnerID int32
+}
+
+func (*OrderTeam) Table() string {
+	return "team"
+}
+
+func (*OrderTeam) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func (*OrderTeam) Indexes() []*Index {
+	return []*Index{
+		NewIndex("idx_owner_id", "owner_id"),
+	}
+}
+
+func (*OrderTeam) ForeignKeys() []*ForeignKey {
+	return []*ForeignKey{
+		NewForeignKey("fk_team_owner", []string{"owner_id"}, "user", []string{"id"}),
+	}
+}
+
+func TestMaker_Generate_KeepForeignKeyChecks(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		KeepForeignKeyChecks: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OrderEntry{}, &OrderUser{}, &OrderTeam{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "DROP TABLE IF EXISTS `entry`, `user`, `team`;\n\n" +
+		"CREATE TABLE `team` (\n" +
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+		"    `owner_id` INTEGER NOT NULL,\n" +
+		"    INDEX `idx_owner_id` (`owner_id`),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+		"CREATE TABLE `user` (\n" +
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+		"    `team_id` INTEGER NOT NULL,\n" +
+		"    INDEX `idx_team_id` (`team_id`),\n" +
+		"    CONSTRAINT `fk_user_team` FOREIGN KEY (`team_id`) REFERENCES `team` (`id`),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+		"CREATE TABLE `entry` (\n" +
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+		"    `user_id` INTEGER NOT NULL,\n" +
+		"    INDEX `idx_user_id` (`user_id`),\n" +
+		"    CONSTRAINT `fk_entry_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;\n\n" +
+		"-- the following constraints have cyclic references, they are added after the tables are created.\n" +
+		"ALTER TABLE `team` ADD CONSTRAINT `fk_team_owner` FOREIGN KEY (`owner_id`) REFERENCES `user` (`id`);\n\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_Generate_KeepForeignKeyChecksRoundTrip(t *testing.T) {
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+		KeepForeignKeyChecks: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OrderEntry{}, &OrderUser{}, &OrderTeam{})
+
+	var buf bytes.Buffer
+	if err := m.Generate(&buf); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSQL(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", buf.String(), err)
+	}
+
+	// the constraint with the cyclic reference is read from the ALTER TABLE statement.
+	var fks []string
+	for _, table := range schema.tables {
+		for _, fk := range table.foreignKeys {
+			fks = append(fks, table.name+"."+fk.name)
+		}
+	}
+	want := []string{"team.fk_team_owner", "user.fk_user_team", "entry.fk_entry_user"}
+	if diff := cmp.Diff(want, fks); diff != "" {
+		t.Errorf("foreign keys are not match: (-want/+got)\n%s", diff)
+	}
+
+	// no changes from the generated schema.
+	buf.Reset()
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("want no changes, got:\n%s", buf.String())
+	}
+}
+
+func TestMaker_Generate_KeepForeignKeyChecksBootstrap(t *testing.T) {
+	m, err := New(&Config{
+		KeepForeignKeyChecks: true,
+		Bootstrap:            true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OrderEntry{}, &OrderUser{}, &OrderTeam{})
+
+	// the cyclic references can't be created twice.
+	var buf bytes.Buffer
+	err = m.Generate(&buf)
+	want := `myddlmaker: foreign key constraint "fk_team_owner" of table "team" has cyclic references, it is not supported in Bootstrap mode`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+func TestSortTables(t *testing.T) {
+	fk := func(name, table string) *ForeignKey {
+		return NewForeignKey(name, []string{table + "_id"}, table, []string{"id"})
+	}
+	tables := []*table{
+		{name: "comment", foreignKeys: []*ForeignKey{fk("fk_comment_entry", "entry")}},
+		{name: "entry", foreignKeys: []*ForeignKey{fk("fk_entry_user", "user")}},
+		{name: "tag"},
+		{name: "team", foreignKeys: []*ForeignKey{fk("fk_team_user", "user")}},
+		{name: "user", foreignKeys: []*ForeignKey{fk("fk_user_team", "team"), fk("fk_user_user", "user")}},
+	}
+
+	sorted, deferred := sortTables(tables)
+	names := make([]string, 0, len(sorted))
+	for _, table := range sorted {
+		names = append(names, table.name)
+	}
+	wantNames := []string{"team", "user", "entry", "comment", "tag"}
+	if diff := cmp.Diff(wantNames, names); diff != "" {
+		t.Errorf("the order of tables is not match: (-want/+got)\n%s", diff)
+	}
+
+	// fk_team_user closes the cycle, it is removed from the table.
+	if len(sorted[0].foreignKeys) != 0 {
+		t.Errorf("want no foreign keys in %q, got %d", sorted[0].name, len(sorted[0].foreignKeys))
+	}
+	if len(tables[3].foreignKeys) != 1 {
+		t.Error("sortTables must not modify the original table")
+	}
+	if len(deferred) != 1 || deferred[0].table != "team" || deferred[0].fk.name != "fk_team_user" {
+		t.Errorf("unexpected deferred constraints: %v", deferred)
+	}
+}
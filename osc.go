@@ -0,0 +1,112 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"strings"
+)
+
+// OnlineSchemaChangeTool is a tool that changes large tables without locking them.
+type OnlineSchemaChangeTool string
+
+const (
+	// OnlineSchemaChangeToolGhost is gh-ost.
+	// https://github.com/github/gh-ost
+	OnlineSchemaChangeToolGhost OnlineSchemaChangeTool = "gh-ost"
+
+	// OnlineSchemaChangeToolPtOSC is pt-online-schema-change of Percona Toolkit.
+	// https://docs.percona.com/percona-toolkit/pt-online-schema-change.html
+	OnlineSchemaChangeToolPtOSC OnlineSchemaChangeTool = "pt-online-schema-change"
+)
+
+// isLargeTable reports whether the table is listed in Config.LargeTables.
+func (m *Maker) isLargeTable(name string) bool {
+	for _, t := range m.config.LargeTables {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChanges writes the statements of the changes.
+// The ALTER TABLE statements of the large tables are merged into one command line of the online schema change tool,
+// and it is written as comments in the place of the first change of the table.
+// Every line of the command is commented out, because the clauses may contain newlines, e.g. the partition definitions.
+func (m *Maker) writeChanges(buf *bytes.Buffer, changes []*change) {
+	// collect the ALTER TABLE clauses of the large tables.
+	alters := map[string][]string{}
+	for _, c := range changes {
+		if isAlterTable(c) && m.isLargeTable(c.table) {
+			alters[c.table] = append(alters[c.table], c.sql)
+		}
+	}
+
+	written := map[string]bool{}
+	for _, c := range changes {
+		if clauses, ok := alters[c.table]; ok && isAlterTable(c) {
+			if written[c.table] {
+				continue
+			}
+			written[c.table] = true
+			buf.WriteString("-- ")
+			buf.WriteString(quote(c.table))
+			buf.WriteString(" is a large table. Run the following command instead of ALTER TABLE.\n")
+			cmd := m.oscCommand(c.table, strings.Join(clauses, ", "))
+			buf.WriteString("-- ")
+			buf.WriteString(strings.ReplaceAll(cmd, "\n", "\n-- "))
+			buf.WriteString("\n")
+			continue
+		}
+		buf.WriteString(m.statement(c))
+		buf.WriteString(";\n")
+	}
+}
+
+// isAlterTable reports whether the change is an ALTER TABLE statement.
+func isAlterTable(c *change) bool {
+	switch c.kind {
+	case changeRenameTable, changeDropTable, changeCreateTable,
+		changeDropSequence, changeCreateSequence, changeAlterSequence:
+		return false
+	}
+	return true
+}
+
+// oscCommand returns the command line of the online schema change tool.
+func (m *Maker) oscCommand(table, alter string) string {
+	var database string
+	if m.config.DB != nil {
+		database = m.config.DB.Name
+	}
+
+	var args []string
+	switch m.config.OnlineSchemaChangeTool {
+	case OnlineSchemaChangeToolPtOSC:
+		dsn := "t=" + table
+		if database != "" {
+			dsn = "D=" + database + "," + dsn
+		}
+		args = []string{
+			"pt-online-schema-change",
+			"--alter=" + shellQuote(alter),
+			shellQuote(dsn),
+			"--execute",
+		}
+	default:
+		args = []string{"gh-ost"}
+		if database != "" {
+			args = append(args, "--database="+shellQuote(database))
+		}
+		args = append(args,
+			"--table="+shellQuote(table),
+			"--alter="+shellQuote(alter),
+			"--execute",
+		)
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,150 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMaker_GenerateDiff_LargeTables(t *testing.T) {
+	tests := []struct {
+		tool OnlineSchemaChangeTool
+		want string
+	}{
+		{
+			tool: OnlineSchemaChangeToolGhost,
+			want: "-- `user` is a large table. Run the following command instead of ALTER TABLE.\n" +
+				"-- gh-ost --database='app' --table='user' --alter='DROP INDEX `idx_age`, MODIFY COLUMN `age` INTEGER NULL, " +
+				"ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`, ADD UNIQUE `uniq_email` (`email`)' --execute\n" +
+				"ALTER TABLE `entry` ADD COLUMN `user_id` INTEGER NOT NULL AFTER `id`;\n" +
+				"ALTER TABLE `entry` ADD INDEX `idx_user_id` (`user_id`);\n" +
+				"ALTER TABLE `entry` ADD CONSTRAINT `fk_entry_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`);\n",
+		},
+		{
+			tool: OnlineSchemaChangeToolPtOSC,
+			want: "-- `user` is a large table. Run the following command instead of ALTER TABLE.\n" +
+				"-- pt-online-schema-change --alter='DROP INDEX `idx_age`, MODIFY COLUMN `age` INTEGER NULL, " +
+				"ADD COLUMN `email` VARCHAR(255) NOT NULL AFTER `name`, ADD UNIQUE `uniq_email` (`email`)' 'D=app,t=user' --execute\n" +
+				"ALTER TABLE `entry` ADD COLUMN `user_id` INTEGER NOT NULL AFTER `id`;\n" +
+				"ALTER TABLE `entry` ADD INDEX `idx_user_id` (`user_id`);\n" +
+				"ALTER TABLE `entry` ADD CONSTRAINT `fk_entry_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`);\n",
+		},
+	}
+
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&DiffUser1{}, &DiffEntry2{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	for _, tt := range tests {
+		m, err := New(&Config{
+			DB: &DBConfig{
+				Name: "app",
+			},
+			LargeTables:            []string{"user"},
+			OnlineSchemaChangeTool: tt.tool,
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddStructs(&DiffUser2{}, &DiffEntry1{})
+
+		var buf bytes.Buffer
+		if err := m.GenerateDiff(&buf, schema); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+			t.Errorf("%s: diff is not match: (-want/+got)\n%s", tt.tool, diff)
+		}
+	}
+}
+
+type OSCLog1 struct {
+	ID        int64 `ddl:",auto"`
+	CreatedAt int32
+}
+
+func (*OSCLog1) Table() string {
+	return "log"
+}
+
+func (*OSCLog1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "created_at")
+}
+
+type OSCLog2 struct {
+	ID        int64 `ddl:",auto"`
+	CreatedAt int32
+}
+
+func (*OSCLog2) Table() string {
+	return "log"
+}
+
+func (*OSCLog2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id", "created_at")
+}
+
+func (*OSCLog2) Partitions() *Partitions {
+	return NewRangePartitions("`created_at`").Add(
+		NewPartition("p2023").LessThan("2024"),
+		NewPartition("pmax").LessThan("MAXVALUE"),
+	)
+}
+
+func TestMaker_GenerateDiff_LargeTablesPartitions(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&OSCLog1{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{
+		LargeTables: []string{"log"},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OSCLog2{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateDiff(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// the partition definitions in the command are commented out.
+	want := "-- `log` is a large table. Run the following command instead of ALTER TABLE.\n" +
+		"-- gh-ost --table='log' --alter='PARTITION BY RANGE (`created_at`) (\n" +
+		"--     PARTITION `p2023` VALUES LESS THAN (2024),\n" +
+		"--     PARTITION `pmax` VALUES LESS THAN (MAXVALUE)\n" +
+		"-- )' --execute\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("diff is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "''"},
+		{"ADD COLUMN `a` INTEGER", "'ADD COLUMN `a` INTEGER'"},
+		{"COMMENT 'it''s'", `'COMMENT '\''it'\'''\''s'\'''`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
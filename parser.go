@@ -0,0 +1,1590 @@
+package myddlmaker
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseSQL parses the CREATE TABLE statements in r
+// and returns the schema that they define.
+// r may be a schema.sql generated by GenerateFile or an output of mysqldump --no-data.
+// The ALTER TABLE statements that add foreign key constraints, such as the constraints with cyclic references
+// that GenerateFile adds after the tables are created, are applied to the tables.
+// The other statements than CREATE TABLE and CREATE SEQUENCE are ignored.
+func ParseSQL(r io.Reader) (*Schema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("myddlmaker: failed to read sql: %w", err)
+	}
+	p, err := newDDLParser(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return p.parse()
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+
+	// tokenWord is a bare word, e.g. keywords, identifiers and numbers.
+	tokenWord
+
+	// tokenQuotedIdent is an identifier quoted by back quotes.
+	tokenQuotedIdent
+
+	// tokenString is a string literal.
+	tokenString
+
+	// tokenSymbol is a symbol, e.g. parentheses, commas and operators.
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+
+	// val is the unquoted value of the token.
+	val string
+
+	// pos and end are the offsets of the token in the source.
+	pos, end int
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	var executable bool
+	i := 0
+	for i < len(src) {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "/*!"):
+			// executable comments, e.g. /*!80000 INVISIBLE */
+			// MySQL Server executes the content, so we parse it too.
+			i += len("/*!")
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			executable = true
+		case strings.HasPrefix(src[i:], "/*T!"):
+			// executable comments of TiDB, e.g. /*T![clustered_index] CLUSTERED */ and /*T! SHARD_ROW_ID_BITS=4 */
+			i += len("/*T!")
+			if i < len(src) && src[i] == '[' {
+				j := strings.IndexByte(src[i:], ']')
+				if j < 0 {
+					return nil, newParseError(src, i, "unterminated comment")
+				}
+				i += j + 1
+			}
+			executable = true
+		case executable && strings.HasPrefix(src[i:], "*/"):
+			i += len("*/")
+			executable = false
+		case ch == '#' || strings.HasPrefix(src[i:], "-- ") || strings.HasPrefix(src[i:], "--\n"):
+			// comments to the end of the line
+			if j := strings.IndexByte(src[i:], '\n'); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			// C-style comments
+			j := strings.Index(src[i+2:], "*/")
+			if j < 0 {
+				return nil, newParseError(src, i, "unterminated comment")
+			}
+			i += j + 4
+		case ch == '`':
+			var buf strings.Builder
+			j := i + 1
+			for {
+				if j >= len(src) {
+					return nil, newParseError(src, i, "unterminated quoted identifier")
+				}
+				if src[j] == '`' {
+					if j+1 < len(src) && src[j+1] == '`' {
+						buf.WriteByte('`')
+						j += 2
+						continue
+					}
+					break
+				}
+				buf.WriteByte(src[j])
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenQuotedIdent, val: buf.String(), pos: i, end: j + 1})
+			i = j + 1
+		case ch == '\'' || ch == '"':
+			val, end, err := unquoteString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, val: val, pos: i, end: end})
+			i = end
+		case isWordChar(ch):
+			j := i
+			for j < len(src) && (isWordChar(src[j]) || (src[j] == '.' && isDigit(src[i]))) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, val: src[i:j], pos: i, end: j})
+			i = j
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, val: src[i : i+1], pos: i, end: i + 1})
+			i++
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(src), end: len(src)})
+	return tokens, nil
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || isDigit(ch) || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch >= 0x80
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// unquoteString unquotes the string literal that starts at src[pos].
+// It returns the unquoted value and the offset of the end of the literal.
+// https://dev.mysql.com/doc/refman/8.0/en/string-literals.html
+func unquoteString(src string, pos int) (string, int, error) {
+	quote := src[pos]
+	var buf strings.Builder
+	i := pos + 1
+	for i < len(src) {
+		ch := src[i]
+		switch {
+		case ch == quote:
+			if i+1 < len(src) && src[i+1] == quote {
+				buf.WriteByte(quote)
+				i += 2
+				continue
+			}
+			return buf.String(), i + 1, nil
+		case ch == '\\' && i+1 < len(src):
+			switch esc := src[i+1]; esc {
+			case '0':
+				buf.WriteByte(0)
+			case 'b':
+				buf.WriteByte('\b')
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'Z':
+				buf.WriteByte('\x1a')
+			case '%', '_':
+				buf.WriteByte('\\')
+				buf.WriteByte(esc)
+			default:
+				buf.WriteByte(esc)
+			}
+			i += 2
+		default:
+			buf.WriteByte(ch)
+			i++
+		}
+	}
+	return "", 0, newParseError(src, pos, "unterminated string literal")
+}
+
+type parseError struct {
+	line int
+	msg  string
+}
+
+func newParseError(src string, pos int, msg string) *parseError {
+	return &parseError{
+		line: strings.Count(src[:pos], "\n") + 1,
+		msg:  msg,
+	}
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("myddlmaker: line %d: %s", e.line, e.msg)
+}
+
+type ddlParser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+func newDDLParser(src string) (*ddlParser, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	return &ddlParser{
+		src:    src,
+		tokens: tokens,
+	}, nil
+}
+
+func (p *ddlParser) errorf(tok token, format string, args ...any) error {
+	return newParseError(p.src, tok.pos, fmt.Sprintf(format, args...))
+}
+
+// peek returns the n-th next token without consuming it.
+func (p *ddlParser) peek(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *ddlParser) next() token {
+	tok := p.peek(0)
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func isKeyword(tok token, keyword string) bool {
+	return tok.kind == tokenWord && strings.EqualFold(tok.val, keyword)
+}
+
+func isSymbol(tok token, symbol string) bool {
+	return tok.kind == tokenSymbol && tok.val == symbol
+}
+
+// acceptKeyword consumes the keywords if the next tokens are the keywords.
+func (p *ddlParser) acceptKeyword(keywords ...string) bool {
+	for i, kw := range keywords {
+		if !isKeyword(p.peek(i), kw) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if isSymbol(p.peek(0), symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) expectKeyword(keywords ...string) error {
+	if !p.acceptKeyword(keywords...) {
+		return p.errorf(p.peek(0), "expected %s, but got %q", strings.Join(keywords, " "), p.peek(0).val)
+	}
+	return nil
+}
+
+func (p *ddlParser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.errorf(p.peek(0), "expected %q, but got %q", symbol, p.peek(0).val)
+	}
+	return nil
+}
+
+// identifier consumes an identifier.
+func (p *ddlParser) identifier() (string, error) {
+	tok := p.peek(0)
+	if tok.kind != tokenWord && tok.kind != tokenQuotedIdent {
+		return "", p.errorf(tok, "expected an identifier, but got %q", tok.val)
+	}
+	p.pos++
+	return tok.val, nil
+}
+
+// tableName consumes a table name that may be qualified by the database name.
+func (p *ddlParser) tableName() (string, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return "", err
+	}
+	if p.acceptSymbol(".") {
+		return p.identifier()
+	}
+	return name, nil
+}
+
+// stringLiteral consumes a string literal.
+func (p *ddlParser) stringLiteral() (string, error) {
+	tok := p.peek(0)
+	if tok.kind != tokenString {
+		return "", p.errorf(tok, "expected a string literal, but got %q", tok.val)
+	}
+	p.pos++
+	return tok.val, nil
+}
+
+// integer consumes an integer.
+func (p *ddlParser) integer() (int, error) {
+	tok := p.peek(0)
+	v, err := strconv.Atoi(tok.val)
+	if tok.kind != tokenWord || err != nil {
+		return 0, p.errorf(tok, "expected an integer, but got %q", tok.val)
+	}
+	p.pos++
+	return v, nil
+}
+
+// skipParens skips the tokens until the parenthesis that closes the current one.
+// It returns the raw source between the parentheses.
+func (p *ddlParser) skipParens() (string, error) {
+	open := p.peek(0)
+	if err := p.expectSymbol("("); err != nil {
+		return "", err
+	}
+	depth := 1
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return "", p.errorf(open, "unbalanced parentheses")
+		case isSymbol(tok, "("):
+			depth++
+		case isSymbol(tok, ")"):
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(p.src[open.end:tok.pos]), nil
+			}
+		}
+	}
+}
+
+// skipStatement skips the tokens until the end of the current statement.
+func (p *ddlParser) skipStatement() {
+	for {
+		tok := p.next()
+		if tok.kind == tokenEOF || isSymbol(tok, ";") {
+			return
+		}
+	}
+}
+
+// expression consumes a simple expression, such as literals, function calls and parenthesized expressions.
+// It returns the raw source of the expression.
+func (p *ddlParser) expression() (string, error) {
+	start := p.peek(0)
+	if isSymbol(start, "-") || isSymbol(start, "+") {
+		p.pos++
+	}
+
+	tok := p.peek(0)
+	switch {
+	case isSymbol(tok, "("):
+		if _, err := p.skipParens(); err != nil {
+			return "", err
+		}
+	case tok.kind == tokenString:
+		p.pos++
+	case isKeyword(tok, "NEXT") && isKeyword(p.peek(1), "VALUE") && isKeyword(p.peek(2), "FOR"):
+		// the next value of the sequence, e.g. NEXT VALUE FOR seq_order.
+		p.pos += 3
+		if _, err := p.tableName(); err != nil {
+			return "", err
+		}
+	case tok.kind == tokenWord:
+		p.pos++
+		next := p.peek(0)
+		if next.pos == tok.end && next.kind == tokenString {
+			// literals with introducers, e.g. _utf8mb4'string', b'0101' and x'ff'.
+			p.pos++
+		} else if next.pos == tok.end && isSymbol(next, "(") {
+			// function calls, e.g. CURRENT_TIMESTAMP(6)
+			if _, err := p.skipParens(); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return "", p.errorf(tok, "expected an expression, but got %q", tok.val)
+	}
+	return p.src[start.pos:p.peek(-1).end], nil
+}
+
+func (p *ddlParser) parse() (*Schema, error) {
+	s := &Schema{}
+	for p.peek(0).kind != tokenEOF {
+		if p.acceptSymbol(";") {
+			continue
+		}
+		if isKeyword(p.peek(0), "CREATE") &&
+			(isKeyword(p.peek(1), "TABLE") || (isKeyword(p.peek(1), "TEMPORARY") && isKeyword(p.peek(2), "TABLE"))) {
+			tbl, err := p.parseCreateTable()
+			if err != nil {
+				return nil, err
+			}
+			s.tables = append(s.tables, tbl)
+			continue
+		}
+		if isKeyword(p.peek(0), "CREATE") &&
+			(isKeyword(p.peek(1), "SEQUENCE") || (isKeyword(p.peek(1), "OR") && isKeyword(p.peek(3), "SEQUENCE"))) {
+			seq, err := p.parseCreateSequence()
+			if err != nil {
+				return nil, err
+			}
+			s.sequences = append(s.sequences, seq)
+			continue
+		}
+		if isKeyword(p.peek(0), "ALTER") && isKeyword(p.peek(1), "TABLE") {
+			if err := p.parseAlterTable(s); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.skipStatement()
+	}
+	return s, nil
+}
+
+// parseCreateTable parses a CREATE TABLE statement.
+// https://dev.mysql.com/doc/refman/8.0/en/create-table.html
+func (p *ddlParser) parseCreateTable() (*table, error) {
+	start := p.peek(0)
+	p.acceptKeyword("CREATE")
+	p.acceptKeyword("TEMPORARY")
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return nil, err
+	}
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+
+	name, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	tbl := &table{
+		name:    name,
+		rawName: snakeToCamel(name),
+	}
+
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.parseCreateDefinition(tbl); err != nil {
+			return nil, err
+		}
+		if p.acceptSymbol(",") {
+			continue
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if err := p.parseTableOptions(tbl); err != nil {
+		return nil, err
+	}
+
+	if tbl.primaryKey == nil {
+		return nil, p.errorf(start, "table %q: primary key is missing", tbl.name)
+	}
+	return tbl, nil
+}
+
+// parseAlterTable parses an ALTER TABLE statement that adds foreign key constraints,
+// and adds them to the table in s.
+// The other ALTER TABLE statements are skipped.
+func (p *ddlParser) parseAlterTable(s *Schema) error {
+	p.acceptKeyword("ALTER")
+	if err := p.expectKeyword("TABLE"); err != nil {
+		return err
+	}
+	tok := p.peek(0)
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if !p.isAddForeignKey() {
+		p.skipStatement()
+		return nil
+	}
+
+	var tbl *table
+	for _, t := range s.tables {
+		if t.name == name {
+			tbl = t
+			break
+		}
+	}
+	if tbl == nil {
+		return p.errorf(tok, "table %q is not found", name)
+	}
+
+	for {
+		p.acceptKeyword("ADD")
+		if err := p.parseCreateDefinition(tbl); err != nil {
+			return err
+		}
+		if !p.acceptSymbol(",") {
+			break
+		}
+		if !p.isAddForeignKey() {
+			return p.errorf(p.peek(0), "table %q: expected ADD FOREIGN KEY, but got %q", name, p.peek(0).val)
+		}
+	}
+	if tok := p.peek(0); tok.kind != tokenEOF && !isSymbol(tok, ";") {
+		return p.errorf(tok, "unexpected %q", tok.val)
+	}
+	return nil
+}
+
+// isAddForeignKey reports whether the next tokens are ADD [CONSTRAINT [symbol]] FOREIGN KEY.
+func (p *ddlParser) isAddForeignKey() bool {
+	if !isKeyword(p.peek(0), "ADD") {
+		return false
+	}
+	if isKeyword(p.peek(1), "CONSTRAINT") {
+		return isKeyword(p.peek(2), "FOREIGN") || isKeyword(p.peek(3), "FOREIGN")
+	}
+	return isKeyword(p.peek(1), "FOREIGN")
+}
+
+// parseCreateSequence parses a CREATE SEQUENCE statement of MariaDB and TiDB.
+// https://mariadb.com/kb/en/create-sequence/
+// https://docs.pingcap.com/tidb/stable/sql-statement-create-sequence
+func (p *ddlParser) parseCreateSequence() (*Sequence, error) {
+	p.acceptKeyword("CREATE")
+	p.acceptKeyword("OR", "REPLACE")
+	if err := p.expectKeyword("SEQUENCE"); err != nil {
+		return nil, err
+	}
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+
+	name, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	seq := &Sequence{
+		name: name,
+	}
+
+	for {
+		tok := p.peek(0)
+		switch {
+		case tok.kind == tokenEOF, isSymbol(tok, ";"):
+			return seq, nil
+		case p.acceptKeyword("START"):
+			if !p.acceptKeyword("WITH") {
+				p.acceptSymbol("=")
+			}
+			v, err := p.signedInteger()
+			if err != nil {
+				return nil, err
+			}
+			seq.start = v
+			seq.hasStart = true
+		case p.acceptKeyword("INCREMENT"):
+			if !p.acceptKeyword("BY") {
+				p.acceptSymbol("=")
+			}
+			v, err := p.signedInteger()
+			if err != nil {
+				return nil, err
+			}
+			seq.increment = v
+		case p.acceptKeyword("MINVALUE"):
+			p.acceptSymbol("=")
+			v, err := p.signedInteger()
+			if err != nil {
+				return nil, err
+			}
+			seq.minValue = v
+			seq.hasMinValue = true
+		case p.acceptKeyword("NO", "MINVALUE"), p.acceptKeyword("NOMINVALUE"):
+			seq.minValue = 0
+			seq.hasMinValue = false
+		case p.acceptKeyword("MAXVALUE"):
+			p.acceptSymbol("=")
+			v, err := p.signedInteger()
+			if err != nil {
+				return nil, err
+			}
+			seq.maxValue = v
+			seq.hasMaxValue = true
+		case p.acceptKeyword("NO", "MAXVALUE"), p.acceptKeyword("NOMAXVALUE"):
+			seq.maxValue = 0
+			seq.hasMaxValue = false
+		case p.acceptKeyword("CACHE"):
+			p.acceptSymbol("=")
+			v, err := p.signedInteger()
+			if err != nil {
+				return nil, err
+			}
+			seq.cache = v
+		case p.acceptKeyword("NOCACHE"), p.acceptKeyword("NO", "CACHE"):
+			// caching one value is equivalent to no cache.
+			seq.cache = 1
+		case p.acceptKeyword("CYCLE"):
+			seq.cycle = true
+		case p.acceptKeyword("NOCYCLE"), p.acceptKeyword("NO", "CYCLE"):
+			seq.cycle = false
+		case p.acceptKeyword("ENGINE"):
+			// SHOW CREATE SEQUENCE writes the storage engine.
+			p.acceptSymbol("=")
+			if _, err := p.identifier(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf(tok, "sequence %q: unknown sequence option: %q", seq.name, tok.val)
+		}
+	}
+}
+
+// signedInteger consumes an integer that may have the minus sign.
+func (p *ddlParser) signedInteger() (int64, error) {
+	start := p.peek(0)
+	sign := ""
+	if p.acceptSymbol("-") {
+		sign = "-"
+	}
+	tok := p.peek(0)
+	v, err := strconv.ParseInt(sign+tok.val, 10, 64)
+	if tok.kind != tokenWord || err != nil {
+		return 0, p.errorf(start, "expected an integer, but got %q", tok.val)
+	}
+	p.pos++
+	return v, nil
+}
+
+func (p *ddlParser) parseCreateDefinition(tbl *table) error {
+	tok := p.peek(0)
+
+	// CONSTRAINT [symbol]
+	var symbol string
+	if isKeyword(tok, "CONSTRAINT") {
+		p.pos++
+		if !isKeyword(p.peek(0), "PRIMARY") && !isKeyword(p.peek(0), "UNIQUE") &&
+			!isKeyword(p.peek(0), "FOREIGN") && !isKeyword(p.peek(0), "CHECK") {
+			name, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			symbol = name
+		}
+		tok = p.peek(0)
+	}
+
+	switch {
+	case isKeyword(tok, "PRIMARY"):
+		p.pos++
+		if err := p.expectKeyword("KEY"); err != nil {
+			return err
+		}
+		p.parseIndexType()
+		parts, err := p.parseKeyParts()
+		if err != nil {
+			return err
+		}
+		columns, err := p.plainColumns(tok, parts)
+		if err != nil {
+			return err
+		}
+		opts, err := p.parseIndexOptions()
+		if err != nil {
+			return err
+		}
+		tbl.primaryKey = NewPrimaryKey(columns...)
+		tbl.primaryKey.clustered = opts.clustered
+		return nil
+
+	case isKeyword(tok, "INDEX"), isKeyword(tok, "KEY"):
+		p.pos++
+		name, parts, opts, err := p.parseIndex("")
+		if err != nil {
+			return err
+		}
+		if opts.parser != "" {
+			return p.errorf(tok, "table %q, index %q: WITH PARSER is only available for FULLTEXT indexes", tbl.name, name)
+		}
+		idx := &Index{name: name, keyParts: parts}
+		idx.comment = opts.comment
+		idx.invisible = opts.invisible
+		tbl.indexes = append(tbl.indexes, idx)
+		return nil
+
+	case isKeyword(tok, "UNIQUE"):
+		p.pos++
+		if !p.acceptKeyword("INDEX") {
+			p.acceptKeyword("KEY")
+		}
+		name, parts, opts, err := p.parseIndex(symbol)
+		if err != nil {
+			return err
+		}
+		if opts.parser != "" {
+			return p.errorf(tok, "table %q, index %q: WITH PARSER is only available for FULLTEXT indexes", tbl.name, name)
+		}
+		idx := &UniqueIndex{name: name, keyParts: parts}
+		idx.comment = opts.comment
+		idx.invisible = opts.invisible
+		tbl.uniqueIndexes = append(tbl.uniqueIndexes, idx)
+		return nil
+
+	case isKeyword(tok, "FULLTEXT"):
+		p.pos++
+		if !p.acceptKeyword("INDEX") {
+			p.acceptKeyword("KEY")
+		}
+		name, parts, opts, err := p.parseIndex("")
+		if err != nil {
+			return err
+		}
+		columns, err := p.plainColumns(tok, parts)
+		if err != nil {
+			return err
+		}
+		if len(columns) != 1 {
+			return p.errorf(tok, "table %q, index %q: FULLTEXT indexes with multiple columns are not supported", tbl.name, name)
+		}
+		idx := NewFullTextIndex(name, columns[0])
+		idx.comment = opts.comment
+		idx.invisible = opts.invisible
+		idx.parser = opts.parser
+		tbl.fullTextIndexes = append(tbl.fullTextIndexes, idx)
+		return nil
+
+	case isKeyword(tok, "SPATIAL"):
+		p.pos++
+		if !p.acceptKeyword("INDEX") {
+			p.acceptKeyword("KEY")
+		}
+		name, parts, opts, err := p.parseIndex("")
+		if err != nil {
+			return err
+		}
+		columns, err := p.plainColumns(tok, parts)
+		if err != nil {
+			return err
+		}
+		if len(columns) != 1 {
+			return p.errorf(tok, "table %q, index %q: SPATIAL indexes with multiple columns are not supported", tbl.name, name)
+		}
+		idx := NewSpatialIndex(name, columns[0])
+		idx.comment = opts.comment
+		idx.invisible = opts.invisible
+		tbl.spatialIndexes = append(tbl.spatialIndexes, idx)
+		return nil
+
+	case isKeyword(tok, "FOREIGN"):
+		p.pos++
+		fk, err := p.parseForeignKey(tbl, symbol)
+		if err != nil {
+			return err
+		}
+		tbl.foreignKeys = append(tbl.foreignKeys, fk)
+		return nil
+
+	case isKeyword(tok, "CHECK"):
+		p.pos++
+		expr, err := p.skipParens()
+		if err != nil {
+			return err
+		}
+		if expr == "" {
+			return p.errorf(tok, "table %q: the expression of CHECK constraint is missing", tbl.name)
+		}
+		if symbol == "" {
+			// MySQL generates the name in the same way.
+			symbol = fmt.Sprintf("%s_chk_%d", tbl.name, len(tbl.checks)+1)
+		}
+		// MySQL encloses the expression with extra parentheses, e.g. CHECK ((`id` > 0)).
+		chk := NewCheck(symbol, trimParens(expr))
+		if p.acceptKeyword("NOT", "ENFORCED") {
+			chk.notEnforced = true
+		} else {
+			p.acceptKeyword("ENFORCED")
+		}
+		tbl.checks = append(tbl.checks, chk)
+		return nil
+	}
+
+	if symbol != "" {
+		return p.errorf(tok, "expected PRIMARY KEY, UNIQUE, FOREIGN KEY or CHECK, but got %q", tok.val)
+	}
+	return p.parseColumn(tbl)
+}
+
+// parseIndexType skips USING {BTREE | HASH}.
+func (p *ddlParser) parseIndexType() {
+	if p.acceptKeyword("USING") {
+		p.next()
+	}
+}
+
+// parseIndex parses [index_name] [index_type] (key_part,...) [index_option] ...
+// If index_name is omitted, name is used.
+func (p *ddlParser) parseIndex(name string) (string, []*keyPart, *indexOptions, error) {
+	if !isSymbol(p.peek(0), "(") && !isKeyword(p.peek(0), "USING") {
+		var err error
+		name, err = p.identifier()
+		if err != nil {
+			return "", nil, nil, err
+		}
+	}
+	p.parseIndexType()
+	parts, err := p.parseKeyParts()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	opts, err := p.parseIndexOptions()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if name == "" {
+		// MySQL uses the name of the first column if the index name is omitted.
+		name = parts[0].column
+		if name == "" {
+			name = "functional_index"
+		}
+	}
+	return name, parts, opts, nil
+}
+
+// parseKeyParts parses (key_part,...).
+func (p *ddlParser) parseKeyParts() ([]*keyPart, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var parts []*keyPart
+	for {
+		part, err := p.parseKeyPart()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		if p.acceptSymbol(",") {
+			continue
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return parts, nil
+	}
+}
+
+// parseKeyPart parses {col_name [(length)] | (expr)} [ASC | DESC].
+func (p *ddlParser) parseKeyPart() (*keyPart, error) {
+	part := &keyPart{}
+	if isSymbol(p.peek(0), "(") {
+		tok := p.peek(0)
+		expr, err := p.skipParens()
+		if err != nil {
+			return nil, err
+		}
+		if expr == "" {
+			return nil, p.errorf(tok, "the expression of the key part is missing")
+		}
+		part.expr = expr
+	} else {
+		col, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		part.column = col
+		if p.acceptSymbol("(") {
+			length, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+			part.length = length
+		}
+	}
+	if p.acceptKeyword("DESC") {
+		part.desc = true
+	} else {
+		p.acceptKeyword("ASC")
+	}
+	return part, nil
+}
+
+// plainColumns returns the column names of the key parts.
+// It is for the indexes that don't support prefix lengths, descending order and functional key parts.
+func (p *ddlParser) plainColumns(tok token, parts []*keyPart) ([]string, error) {
+	columns := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.column == "" || part.length > 0 || part.desc {
+			return nil, p.errorf(tok, "key part %q: prefix lengths, descending indexes and functional key parts are not supported", part.String())
+		}
+		columns = append(columns, part.column)
+	}
+	return columns, nil
+}
+
+type indexOptions struct {
+	comment   string
+	invisible bool
+	parser    string
+
+	// clustered is CLUSTERED or NONCLUSTERED of TiDB primary keys.
+	clustered string
+}
+
+// parseIndexOptions parses index_option ...
+func (p *ddlParser) parseIndexOptions() (*indexOptions, error) {
+	opts := &indexOptions{}
+	for {
+		switch {
+		case p.acceptKeyword("COMMENT"):
+			comment, err := p.stringLiteral()
+			if err != nil {
+				return nil, err
+			}
+			opts.comment = comment
+		case p.acceptKeyword("INVISIBLE"):
+			opts.invisible = true
+		case p.acceptKeyword("VISIBLE"):
+			opts.invisible = false
+		case p.acceptKeyword("IGNORED"):
+			// the invisible indexes of MariaDB.
+			opts.invisible = true
+		case p.acceptKeyword("NOT", "IGNORED"):
+			opts.invisible = false
+		case p.acceptKeyword("CLUSTERED"):
+			opts.clustered = "CLUSTERED"
+		case p.acceptKeyword("NONCLUSTERED"):
+			opts.clustered = "NONCLUSTERED"
+		case p.acceptKeyword("WITH", "PARSER"):
+			parser, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			opts.parser = parser
+		case p.acceptKeyword("KEY_BLOCK_SIZE"):
+			p.acceptSymbol("=")
+			if _, err := p.integer(); err != nil {
+				return nil, err
+			}
+		case isKeyword(p.peek(0), "USING"):
+			p.parseIndexType()
+		default:
+			return opts, nil
+		}
+	}
+}
+
+// parseForeignKey parses FOREIGN KEY [index_name] (col_name,...) reference_definition.
+func (p *ddlParser) parseForeignKey(tbl *table, symbol string) (*ForeignKey, error) {
+	if err := p.expectKeyword("KEY"); err != nil {
+		return nil, err
+	}
+	if !isSymbol(p.peek(0), "(") {
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		if symbol == "" {
+			symbol = name
+		}
+	}
+	tok := p.peek(0)
+	parts, err := p.parseKeyParts()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := p.plainColumns(tok, parts)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("REFERENCES"); err != nil {
+		return nil, err
+	}
+	ref, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+	tok = p.peek(0)
+	parts, err = p.parseKeyParts()
+	if err != nil {
+		return nil, err
+	}
+	references, err := p.plainColumns(tok, parts)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) != len(references) {
+		return nil, p.errorf(p.peek(-1), "table %q, foreign key %q: columns and references must have same length", tbl.name, symbol)
+	}
+	if symbol == "" {
+		// MySQL generates the name if the constraint name is omitted.
+		symbol = fmt.Sprintf("%s_ibfk_%d", tbl.name, len(tbl.foreignKeys)+1)
+	}
+	fk := NewForeignKey(symbol, columns, ref, references)
+
+	if p.acceptKeyword("MATCH") {
+		p.next()
+	}
+	for p.acceptKeyword("ON") {
+		var opt *ForeignKeyOption
+		switch {
+		case p.acceptKeyword("DELETE"):
+			opt = &fk.onDelete
+		case p.acceptKeyword("UPDATE"):
+			opt = &fk.onUpdate
+		default:
+			return nil, p.errorf(p.peek(0), "expected DELETE or UPDATE, but got %q", p.peek(0).val)
+		}
+		switch {
+		case p.acceptKeyword("RESTRICT"):
+			*opt = ForeignKeyOptionRestrict
+		case p.acceptKeyword("CASCADE"):
+			*opt = ForeignKeyOptionCascade
+		case p.acceptKeyword("SET", "NULL"):
+			*opt = ForeignKeyOptionSetNull
+		case p.acceptKeyword("NO", "ACTION"):
+			*opt = "NO ACTION"
+		case p.acceptKeyword("SET", "DEFAULT"):
+			*opt = "SET DEFAULT"
+		default:
+			return nil, p.errorf(p.peek(0), "unknown referential action: %q", p.peek(0).val)
+		}
+	}
+	return fk, nil
+}
+
+var integerTypes = map[string]bool{
+	"TINYINT":   true,
+	"SMALLINT":  true,
+	"MEDIUMINT": true,
+	"INTEGER":   true,
+	"BIGINT":    true,
+}
+
+// parseColumn parses col_name column_definition.
+func (p *ddlParser) parseColumn(tbl *table) error {
+	name, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	col := &column{
+		name:    name,
+		rawName: snakeToCamel(name),
+
+		// the columns accept NULL values if neither NULL nor NOT NULL is specified.
+		null: true,
+	}
+
+	if err := p.parseDataType(col); err != nil {
+		return err
+	}
+
+	// column attributes
+	for {
+		tok := p.peek(0)
+		switch {
+		case isSymbol(tok, ","), isSymbol(tok, ")"):
+			tbl.columns = append(tbl.columns, col)
+			return nil
+		case p.acceptKeyword("UNSIGNED"):
+			col.unsigned = true
+		case p.acceptKeyword("SIGNED"):
+			col.unsigned = false
+		case p.acceptKeyword("CHARACTER", "SET"), p.acceptKeyword("CHARSET"):
+			charset, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			col.charset = charset
+		case p.acceptKeyword("COLLATE"):
+			collate, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			col.collate = collate
+		case p.acceptKeyword("NOT", "NULL"):
+			col.null = false
+		case p.acceptKeyword("NULL"):
+			col.null = true
+		case p.acceptKeyword("DEFAULT"):
+			def, err := p.expression()
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(def, "NULL") {
+				col.def = def
+			}
+		case p.acceptKeyword("ON", "UPDATE"):
+			expr, err := p.expression()
+			if err != nil {
+				return err
+			}
+			col.onUpdate = expr
+		case p.acceptKeyword("AUTO_INCREMENT"):
+			col.autoIncr = true
+		case p.acceptKeyword("AUTO_RANDOM"):
+			col.autoRandom = true
+			if p.acceptSymbol("(") {
+				bits, err := p.integer()
+				if err != nil {
+					return err
+				}
+				col.shardBits = bits
+				if p.acceptSymbol(",") {
+					// the range bits are not supported.
+					if _, err := p.integer(); err != nil {
+						return err
+					}
+				}
+				if err := p.expectSymbol(")"); err != nil {
+					return err
+				}
+			}
+		case p.acceptKeyword("GENERATED", "ALWAYS", "AS"), p.acceptKeyword("AS"):
+			expr, err := p.skipParens()
+			if err != nil {
+				return err
+			}
+			col.generated = expr
+		case p.acceptKeyword("VIRTUAL"):
+			col.stored = false
+		case p.acceptKeyword("STORED"):
+			col.stored = true
+		case p.acceptKeyword("INVISIBLE"):
+			col.invisible = true
+		case p.acceptKeyword("VISIBLE"):
+			col.invisible = false
+		case p.acceptKeyword("COMMENT"):
+			comment, err := p.stringLiteral()
+			if err != nil {
+				return err
+			}
+			col.comment = comment
+		case p.acceptKeyword("CHECK"):
+			tok := p.peek(0)
+			expr, err := p.skipParens()
+			if err != nil {
+				return err
+			}
+			if !isJSONValid(expr, col.name) {
+				return p.errorf(tok, "column %q: unsupported check constraint: %q", col.name, expr)
+			}
+			// JSON columns of MariaDB.
+			col.json = true
+		case p.acceptKeyword("SRID"):
+			srid, err := p.integer()
+			if err != nil {
+				return err
+			}
+			col.srid = srid
+		case p.acceptKeyword("PRIMARY", "KEY"):
+			tbl.primaryKey = NewPrimaryKey(col.name)
+			if p.acceptKeyword("CLUSTERED") {
+				tbl.primaryKey = tbl.primaryKey.Clustered()
+			} else if p.acceptKeyword("NONCLUSTERED") {
+				tbl.primaryKey = tbl.primaryKey.NonClustered()
+			}
+		case p.acceptKeyword("UNIQUE"):
+			p.acceptKeyword("KEY")
+			tbl.uniqueIndexes = append(tbl.uniqueIndexes, NewUniqueIndex(col.name, col.name))
+		default:
+			return p.errorf(tok, "column %q: unknown column attribute: %q", col.name, tok.val)
+		}
+	}
+}
+
+// isJSONValid reports whether expr is JSON_VALID(name).
+func isJSONValid(expr, name string) bool {
+	tokens, err := tokenize(expr)
+	if err != nil || len(tokens) != 5 { // including tokenEOF
+		return false
+	}
+	return isKeyword(tokens[0], "JSON_VALID") && isSymbol(tokens[1], "(") &&
+		(tokens[2].kind == tokenWord || tokens[2].kind == tokenQuotedIdent) && tokens[2].val == name &&
+		isSymbol(tokens[3], ")")
+}
+
+// parseDataType parses data_type without attributes, e.g. VARCHAR(191), DECIMAL(9,6).
+func (p *ddlParser) parseDataType(col *column) error {
+	tok := p.peek(0)
+	if tok.kind != tokenWord {
+		return p.errorf(tok, "column %q: expected a data type, but got %q", col.name, tok.val)
+	}
+	p.pos++
+	typ := strings.ToUpper(tok.val)
+	switch typ {
+	case "INT":
+		typ = "INTEGER"
+	case "BOOL", "BOOLEAN":
+		typ = "TINYINT"
+		col.size = 1
+	}
+	col.typ = typ
+	if (typ == "ENUM" || typ == "SET") && isSymbol(p.peek(0), "(") {
+		values, err := p.parseValues()
+		if err != nil {
+			return err
+		}
+		col.values = values
+		return nil
+	}
+	if isSymbol(p.peek(0), "(") {
+		args, err := p.parseTypeArgs()
+		if err != nil {
+			return err
+		}
+		if (typ == "DECIMAL" || typ == "NUMERIC") && len(args) <= 2 {
+			precision, err := strconv.Atoi(args[0])
+			if err != nil {
+				return p.errorf(tok, "column %q: invalid precision: %q", col.name, args[0])
+			}
+			col.precision = precision
+			col.scale = 0
+			if len(args) == 2 {
+				scale, err := strconv.Atoi(args[1])
+				if err != nil {
+					return p.errorf(tok, "column %q: invalid scale: %q", col.name, args[1])
+				}
+				col.scale = scale
+			}
+			return nil
+		}
+		size, err := strconv.Atoi(args[0])
+		switch {
+		case len(args) == 1 && err == nil && integerTypes[typ]:
+			// the display width of integer types is deprecated.
+			// however TINYINT(1) is a conventional boolean type.
+			if typ == "TINYINT" && size == 1 {
+				col.size = 1
+			}
+		case len(args) == 1 && err == nil:
+			col.size = size
+		default:
+			col.typ = typ + "(" + strings.Join(args, ",") + ")"
+		}
+	}
+	return nil
+}
+
+// parseTypeArgs parses the arguments of data types, e.g. (191), (9,6), ('a','b').
+func (p *ddlParser) parseTypeArgs() ([]string, error) {
+	open := p.peek(0)
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var args []string
+	start := p.peek(0).pos
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.errorf(open, "unbalanced parentheses")
+		case isSymbol(tok, ","):
+			args = append(args, strings.TrimSpace(p.src[start:tok.pos]))
+			start = tok.end
+		case isSymbol(tok, ")"):
+			args = append(args, strings.TrimSpace(p.src[start:tok.pos]))
+			return args, nil
+		}
+	}
+}
+
+// parseValues parses the permitted values of ENUM and SET, e.g. ('active','inactive').
+func (p *ddlParser) parseValues() ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		tok := p.next()
+		if tok.kind != tokenString {
+			return nil, p.errorf(tok, "expected a string literal, but got %q", tok.val)
+		}
+		values = append(values, tok.val)
+
+		tok = p.next()
+		switch {
+		case isSymbol(tok, ","):
+			continue
+		case isSymbol(tok, ")"):
+			return values, nil
+		}
+		return nil, p.errorf(tok, "expected ',' or ')', but got %q", tok.val)
+	}
+}
+
+// parseTableOptions parses table_options and partition_options.
+func (p *ddlParser) parseTableOptions(tbl *table) error {
+	for {
+		tok := p.peek(0)
+		switch {
+		case tok.kind == tokenEOF, isSymbol(tok, ";"):
+			return nil
+		case isKeyword(tok, "PARTITION"):
+			return p.parsePartitions(tbl)
+		case isSymbol(tok, ","):
+			p.pos++
+			continue
+		}
+
+		p.acceptKeyword("DEFAULT")
+		name := strings.ToUpper(p.next().val)
+		if name == "CHARACTER" {
+			if err := p.expectKeyword("SET"); err != nil {
+				return err
+			}
+			name = "CHARSET"
+		}
+		p.acceptSymbol("=")
+
+		var value string
+		valueTok := p.peek(0)
+		if isSymbol(valueTok, "(") {
+			// e.g. UNION = (tbl_name[,tbl_name]...)
+			v, err := p.skipParens()
+			if err != nil {
+				return err
+			}
+			value = v
+		} else {
+			tok := p.next()
+			if tok.kind == tokenEOF || tok.kind == tokenSymbol {
+				return p.errorf(tok, "table %q: expected a value of the table option %s, but got %q", tbl.name, name, tok.val)
+			}
+			value = tok.val
+		}
+
+		// AUTO_INCREMENT is ignored.
+		// it is the current value of the counter in the outputs of mysqldump, not a part of the schema.
+		switch name {
+		case "ENGINE":
+			tbl.engine = value
+		case "CHARSET":
+			tbl.charset = value
+		case "COLLATE":
+			tbl.collate = value
+		case "ROW_FORMAT":
+			tbl.rowFormat = strings.ToUpper(value)
+		case "COMMENT":
+			tbl.comment = value
+		case "KEY_BLOCK_SIZE":
+			size, err := strconv.Atoi(value)
+			if err != nil {
+				return p.errorf(valueTok, "table %q: invalid KEY_BLOCK_SIZE: %q", tbl.name, value)
+			}
+			tbl.keyBlockSize = size
+		case "STATS_PERSISTENT":
+			tbl.statsPersistent = strings.ToUpper(value)
+		case "SHARD_ROW_ID_BITS":
+			bits, err := strconv.Atoi(value)
+			if err != nil {
+				return p.errorf(valueTok, "table %q: invalid SHARD_ROW_ID_BITS: %q", tbl.name, value)
+			}
+			tbl.shardRowIDBits = bits
+		}
+	}
+}
+
+// parsePartitions parses partition_options.
+// https://dev.mysql.com/doc/refman/8.0/en/create-table.html#create-table-partitioning
+func (p *ddlParser) parsePartitions(tbl *table) error {
+	if err := p.expectKeyword("PARTITION", "BY"); err != nil {
+		return err
+	}
+	parts := &Partitions{}
+	parts.linear = p.acceptKeyword("LINEAR")
+
+	tok := p.next()
+	typ := strings.ToUpper(tok.val)
+	switch typ {
+	case "HASH":
+		expr, err := p.skipParens()
+		if err != nil {
+			return err
+		}
+		parts.typ, parts.expr = typ, expr
+	case "KEY":
+		if isKeyword(p.peek(0), "ALGORITHM") {
+			return p.errorf(p.peek(0), "table %q: ALGORITHM of KEY partitioning is not supported", tbl.name)
+		}
+		columns, err := p.parseColumnNames()
+		if err != nil {
+			return err
+		}
+		parts.typ, parts.columns = typ, columns
+	case "RANGE", "LIST":
+		parts.typ = typ
+		if p.acceptKeyword("COLUMNS") {
+			columns, err := p.parseColumnNames()
+			if err != nil {
+				return err
+			}
+			parts.columns = columns
+		} else {
+			expr, err := p.skipParens()
+			if err != nil {
+				return err
+			}
+			parts.expr = expr
+		}
+	default:
+		return p.errorf(tok, "table %q: unknown partitioning type: %q", tbl.name, tok.val)
+	}
+
+	if p.acceptKeyword("PARTITIONS") {
+		count, err := p.integer()
+		if err != nil {
+			return err
+		}
+		parts.count = count
+	}
+	if isKeyword(p.peek(0), "SUBPARTITION") {
+		return p.errorf(p.peek(0), "table %q: subpartitioning is not supported", tbl.name)
+	}
+
+	if p.acceptSymbol("(") {
+		for {
+			def, err := p.parsePartition(tbl)
+			if err != nil {
+				return err
+			}
+			parts.definitions = append(parts.definitions, def)
+			if p.acceptSymbol(")") {
+				break
+			}
+			if err := p.expectSymbol(","); err != nil {
+				return err
+			}
+		}
+	}
+	tbl.partitions = parts
+	return nil
+}
+
+// parsePartition parses a partition_definition.
+func (p *ddlParser) parsePartition(tbl *table) (*Partition, error) {
+	if err := p.expectKeyword("PARTITION"); err != nil {
+		return nil, err
+	}
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	def := &Partition{
+		name: name,
+	}
+
+	switch {
+	case p.acceptKeyword("VALUES", "LESS", "THAN"):
+		def.op = "LESS THAN"
+		if p.acceptKeyword("MAXVALUE") {
+			// SHOW CREATE TABLE omits the parentheses of MAXVALUE.
+			def.values = []string{"MAXVALUE"}
+		} else {
+			values, err := p.parseExpressionList()
+			if err != nil {
+				return nil, err
+			}
+			def.values = values
+		}
+	case p.acceptKeyword("VALUES", "IN"):
+		def.op = "IN"
+		values, err := p.parseExpressionList()
+		if err != nil {
+			return nil, err
+		}
+		def.values = values
+	}
+
+	for {
+		tok := p.peek(0)
+		switch {
+		case isSymbol(tok, ","), isSymbol(tok, ")"):
+			return def, nil
+		case p.acceptKeyword("STORAGE", "ENGINE"), p.acceptKeyword("ENGINE"):
+			// the partitions of InnoDB tables are always InnoDB.
+			p.acceptSymbol("=")
+			if _, err := p.identifier(); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("COMMENT"):
+			p.acceptSymbol("=")
+			comment, err := p.stringLiteral()
+			if err != nil {
+				return nil, err
+			}
+			def.comment = comment
+		default:
+			return nil, p.errorf(tok, "table %q, partition %q: unsupported partition option: %q", tbl.name, name, tok.val)
+		}
+	}
+}
+
+// parseColumnNames parses a parenthesized list of column names, e.g. (`a`, `b`).
+// The list may be empty.
+func (p *ddlParser) parseColumnNames() ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	if p.acceptSymbol(")") {
+		return names, nil
+	}
+	for {
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.acceptSymbol(")") {
+			return names, nil
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseExpressionList parses a parenthesized list of expressions, e.g. (1, 'a', TO_DAYS('2023-01-01')).
+// It returns the raw sources of the expressions.
+func (p *ddlParser) parseExpressionList() ([]string, error) {
+	open := p.peek(0)
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var exprs []string
+	start := p.peek(0).pos
+	depth := 0
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return nil, p.errorf(open, "unbalanced parentheses")
+		case isSymbol(tok, "("):
+			depth++
+		case isSymbol(tok, ",") && depth == 0:
+			exprs = append(exprs, strings.TrimSpace(p.src[start:tok.pos]))
+			start = tok.end
+		case isSymbol(tok, ")") && depth == 0:
+			exprs = append(exprs, strings.TrimSpace(p.src[start:tok.pos]))
+			return exprs, nil
+		case isSymbol(tok, ")"):
+			depth--
+		}
+	}
+}
+
+// parseColumnList parses a comma-separated list of column names, e.g. `a`,`b`.
+func parseColumnList(s string) ([]string, error) {
+	p, err := newDDLParser("(" + s + ")")
+	if err != nil {
+		return nil, err
+	}
+	return p.parseColumnNames()
+}
+
+// parseExpressions parses a comma-separated list of expressions, e.g. 1,'a',MAXVALUE.
+func parseExpressions(s string) ([]string, error) {
+	p, err := newDDLParser("(" + s + ")")
+	if err != nil {
+		return nil, err
+	}
+	return p.parseExpressionList()
+}
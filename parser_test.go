@@ -0,0 +1,333 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseSQL_RoundTrip(t *testing.T) {
+	structs := [][]any{
+		{&Foo1{}},
+		{&Foo2{}},
+		{&Foo3{}},
+		{&Foo1{}, &Foo4{}},
+		{&Foo5{}, &Foo1{}},
+		{&Foo6{}},
+		{&Foo7{}},
+		{&Foo8{}},
+		{&Foo9{}},
+		{&Foo10{}},
+		{&Foo11{}},
+		{&Foo20{}},
+		{&Foo21{}},
+		{&Foo22{}},
+		{&Foo24{}},
+		{&Foo25{}},
+		{&Foo27{}},
+		{&Foo29{}},
+		{&Foo33{}},
+		{&Foo35{}},
+		{&Foo37{}},
+		{&Foo39{}},
+	}
+
+	for _, s := range structs {
+		m, err := New(&Config{
+			DB: &DBConfig{
+				Engine:  "InnoDB",
+				Charset: "utf8mb4",
+				Collate: "utf8mb4_bin",
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		m.AddStructs(s...)
+
+		var want bytes.Buffer
+		if err := m.Generate(&want); err != nil {
+			t.Fatalf("failed to generate ddl: %v", err)
+		}
+
+		schema, err := ParseSQL(bytes.NewReader(want.Bytes()))
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", want.String(), err)
+		}
+
+		// regenerate the ddl from the parsed schema without DBConfig.
+		m0, err := New(&Config{})
+		if err != nil {
+			t.Fatalf("failed to initialize Maker: %v", err)
+		}
+		var got bytes.Buffer
+		got.WriteString("SET foreign_key_checks=0;\n")
+		for _, table := range schema.tables {
+			m0.generateTable(&got, table)
+		}
+		got.WriteString("SET foreign_key_checks=1;\n")
+
+		if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+			t.Errorf("ddl is not match: (-want/+got)\n%s", diff)
+		}
+	}
+}
+
+func TestParseSQL_MySQLDump(t *testing.T) {
+	dump := "-- MySQL dump 10.13  Distrib 8.0.31, for Linux (x86_64)\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+		"DROP TABLE IF EXISTS `user`;\n" +
+		"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+		"CREATE TABLE `user` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(191) COLLATE utf8mb4_bin NOT NULL DEFAULT 'John Doe' COMMENT 'user''s name',\n" +
+		"  `active` tinyint(1) NOT NULL DEFAULT '1',\n" +
+		"  `score` int(11) DEFAULT NULL,\n" +
+		"  `price` decimal(9,6) NOT NULL,\n" +
+		"  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),\n" +
+		"  `updated_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uniq_name` (`name`) COMMENT 'unique name',\n" +
+		"  KEY `idx_score` (`score`,`active`) /*!80000 INVISIBLE */,\n" +
+		"  KEY `idx_name_created_at` (`name`(32),`created_at` DESC),\n" +
+		"  KEY `idx_lower_name` ((lower(`name`))),\n" +
+		"  FULLTEXT KEY `idx_ft_name` (`name`) /*!50100 WITH PARSER `ngram` */ \n" +
+		") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin STATS_PERSISTENT=0 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 COMMENT='users';\n" +
+		"/*!40101 SET character_set_client = @saved_cs_client */;\n" +
+		"\n" +
+		"CREATE TABLE `entry` (\n" +
+		"  `id` bigint unsigned NOT NULL,\n" +
+		"  `user_id` bigint unsigned NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `user_id` (`user_id`),\n" +
+		"  CONSTRAINT `entry_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,\n" +
+		"  CONSTRAINT `entry_chk_1` CHECK ((`id` > 0)) /*!80016 NOT ENFORCED */\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;\n"
+
+	schema, err := ParseSQL(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*table{
+		{
+			name:    "user",
+			rawName: "User",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", unsigned: true, autoIncr: true},
+				{name: "name", rawName: "Name", typ: "VARCHAR", size: 191, collate: "utf8mb4_bin", def: "'John Doe'", comment: "user's name"},
+				{name: "active", rawName: "Active", typ: "TINYINT", size: 1, def: "'1'"},
+				{name: "score", rawName: "Score", typ: "INTEGER", null: true},
+				{name: "price", rawName: "Price", typ: "DECIMAL", precision: 9, scale: 6},
+				{name: "created_at", rawName: "CreatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)"},
+				{name: "updated_at", rawName: "UpdatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", onUpdate: "CURRENT_TIMESTAMP(6)"},
+			},
+			primaryKey: NewPrimaryKey("id"),
+			indexes: []*Index{
+				NewIndex("idx_score", "score", "active").Invisible(),
+				NewIndex("idx_name_created_at", "name(32)", "created_at DESC"),
+				NewIndex("idx_lower_name", "(lower(`name`))"),
+			},
+			uniqueIndexes: []*UniqueIndex{
+				NewUniqueIndex("uniq_name", "name").Comment("unique name"),
+			},
+			fullTextIndexes: []*FullTextIndex{
+				NewFullTextIndex("idx_ft_name", "name").WithParser("ngram"),
+			},
+			engine:          "InnoDB",
+			charset:         "utf8mb4",
+			collate:         "utf8mb4_bin",
+			rowFormat:       "COMPRESSED",
+			comment:         "users",
+			keyBlockSize:    8,
+			statsPersistent: "0",
+		},
+		{
+			name:    "entry",
+			rawName: "Entry",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", unsigned: true},
+				{name: "user_id", rawName: "UserID", typ: "BIGINT", unsigned: true},
+			},
+			primaryKey: NewPrimaryKey("id"),
+			indexes: []*Index{
+				NewIndex("user_id", "user_id"),
+			},
+			foreignKeys: []*ForeignKey{
+				NewForeignKey("entry_ibfk_1", []string{"user_id"}, "user", []string{"id"}).OnDelete(ForeignKeyOptionCascade),
+			},
+			checks: []*CheckConstraint{
+				NewCheck("entry_chk_1", "`id` > 0").NotEnforced(),
+			},
+			engine:  "InnoDB",
+			charset: "utf8mb4",
+			collate: "utf8mb4_bin",
+		},
+	}
+	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, keyPart{}, ForeignKey{}, CheckConstraint{}, FullTextIndex{}, SpatialIndex{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, schema.tables, opt1, opt2); diff != "" {
+		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestParseSQL_TiDB(t *testing.T) {
+	// the output of SHOW CREATE TABLE of TiDB.
+	dump := "CREATE TABLE `user` (\n" +
+		"  `id` bigint NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,\n" +
+		"  `name` varchar(191) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![auto_rand_base] AUTO_RANDOM_BASE=30001 */;\n" +
+		"\n" +
+		"CREATE TABLE `log` (\n" +
+		"  `id` bigint NOT NULL,\n" +
+		"  PRIMARY KEY (`id`) /*T![clustered_index] NONCLUSTERED */\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T! SHARD_ROW_ID_BITS=4 */;\n"
+
+	schema, err := ParseSQL(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*table{
+		{
+			name:    "user",
+			rawName: "User",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT", autoRandom: true, shardBits: 5},
+				{name: "name", rawName: "Name", typ: "VARCHAR", size: 191},
+			},
+			primaryKey: NewPrimaryKey("id").Clustered(),
+			engine:     "InnoDB",
+			charset:    "utf8mb4",
+			collate:    "utf8mb4_bin",
+		},
+		{
+			name:    "log",
+			rawName: "Log",
+			columns: []*column{
+				{name: "id", rawName: "ID", typ: "BIGINT"},
+			},
+			primaryKey:     NewPrimaryKey("id").NonClustered(),
+			engine:         "InnoDB",
+			charset:        "utf8mb4",
+			collate:        "utf8mb4_bin",
+			shardRowIDBits: 4,
+		},
+	}
+	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, schema.tables, opt1, opt2); diff != "" {
+		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestParseSQL_Partitions(t *testing.T) {
+	// the outputs of SHOW CREATE TABLE.
+	dump := "CREATE TABLE `log` (\n" +
+		"  `id` bigint NOT NULL,\n" +
+		"  `created_at` datetime NOT NULL,\n" +
+		"  PRIMARY KEY (`id`,`created_at`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+		"/*!50500 PARTITION BY RANGE  COLUMNS(created_at)\n" +
+		"(PARTITION p2022 VALUES LESS THAN ('2023-01-01 00:00:00') ENGINE = InnoDB,\n" +
+		" PARTITION pmax VALUES LESS THAN (MAXVALUE) COMMENT = 'the rest' ENGINE = InnoDB) */;\n" +
+		"\n" +
+		"CREATE TABLE `event` (\n" +
+		"  `id` bigint NOT NULL,\n" +
+		"  `created_at` datetime NOT NULL,\n" +
+		"  PRIMARY KEY (`id`,`created_at`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+		"/*!50100 PARTITION BY RANGE (year(`created_at`))\n" +
+		"(PARTITION p2022 VALUES LESS THAN (2023) ENGINE = InnoDB,\n" +
+		" PARTITION pmax VALUES LESS THAN MAXVALUE ENGINE = InnoDB) */;\n" +
+		"\n" +
+		"CREATE TABLE `region` (\n" +
+		"  `id` bigint NOT NULL,\n" +
+		"  `region` varchar(16) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`,`region`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+		"/*!50500 PARTITION BY LIST  COLUMNS(id,region)\n" +
+		"(PARTITION p0 VALUES IN ((1,'east'),(2,'west')) ENGINE = InnoDB) */;\n" +
+		"\n" +
+		"CREATE TABLE `session` (\n" +
+		"  `id` bigint NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+		"/*!50100 PARTITION BY LINEAR KEY (id)\n" +
+		"PARTITIONS 4 */;\n"
+
+	schema, err := ParseSQL(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Partitions{
+		NewRangeColumnsPartitions("created_at").Add(
+			NewPartition("p2022").LessThan("'2023-01-01 00:00:00'"),
+			NewPartition("pmax").LessThan("MAXVALUE").Comment("the rest"),
+		),
+		NewRangePartitions("year(`created_at`)").Add(
+			NewPartition("p2022").LessThan("2023"),
+			NewPartition("pmax").LessThan("MAXVALUE"),
+		),
+		NewListColumnsPartitions("id", "region").Add(
+			NewPartition("p0").In("(1,'east')", "(2,'west')"),
+		),
+		NewKeyPartitions("id").Linear().Count(4),
+	}
+	var got []*Partitions
+	for _, table := range schema.tables {
+		got = append(got, table.partitions)
+	}
+	opt := cmp.AllowUnexported(Partitions{}, Partition{})
+	if diff := cmp.Diff(want, got, opt); diff != "" {
+		t.Errorf("partitions are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestParseSQL_Error(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "CREATE TABLE `foo` (\n  `id` INTEGER NOT NULL\n);",
+			want: `myddlmaker: line 1: table "foo": primary key is missing`,
+		},
+		{
+			sql:  "CREATE TABLE `foo` (\n  `id` INTEGER NOT NULL UNKNOWN_ATTRIBUTE,\n  PRIMARY KEY (`id`)\n);",
+			want: `myddlmaker: line 2: column "id": unknown column attribute: "UNKNOWN_ATTRIBUTE"`,
+		},
+		{
+			sql:  "CREATE TABLE `foo` (\n  `id` INTEGER NOT NULL COMMENT 'unterminated",
+			want: `myddlmaker: line 2: unterminated string literal`,
+		},
+		{
+			sql:  "CREATE TABLE `foo` (\n  `id` INTEGER NOT NULL,\n  PRIMARY KEY (`id`)\n)\nPARTITION BY HASH (`id`) PARTITIONS 2 SUBPARTITION BY KEY (`id`);",
+			want: `myddlmaker: line 5: table "foo": subpartitioning is not supported`,
+		},
+		{
+			sql:  "CREATE TABLE `foo` (\n  `id` INTEGER NOT NULL,\n  PRIMARY KEY (`id`)\n)\nPARTITION BY RANGE (`id`) (PARTITION p0 VALUES LESS THAN (10) DATA DIRECTORY = '/data');",
+			want: `myddlmaker: line 5: table "foo", partition "p0": unsupported partition option: "DATA"`,
+		},
+		{
+			sql:  "ALTER TABLE `foo` ADD CONSTRAINT `fk_foo_bar` FOREIGN KEY (`bar_id`) REFERENCES `bar` (`id`);",
+			want: `myddlmaker: line 1: table "foo" is not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseSQL(strings.NewReader(tt.sql))
+		if err == nil {
+			t.Errorf("%q: want some error, but not", tt.sql)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%q: unexpected error: got %q, want %q", tt.sql, err.Error(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package myddlmaker
+
+import (
+	"fmt"
+)
+
+// renamedFrom is the interface for renamed tables.
+// RenamedFrom returns the old names of the table,
+// and GenerateDiff renames the table instead of dropping and re-creating it.
+type renamedFrom interface {
+	RenamedFrom() []string
+}
+
+// renames maps the names in the new schema to the names in the old schema.
+type renames struct {
+	// tables maps the new table names to the old table names.
+	tables map[string]string
+
+	// columns maps the new table names to the maps from the new column names to the old column names.
+	columns map[string]map[string]string
+}
+
+// findRenames finds the renamed tables and columns in to.
+// The old names must exist in from, unless from is empty.
+func findRenames(from, to []*table) (*renames, error) {
+	r := &renames{
+		tables:  map[string]string{},
+		columns: map[string]map[string]string{},
+	}
+	fromMap := make(map[string]*table, len(from))
+	for _, table := range from {
+		fromMap[table.name] = table
+	}
+	toMap := make(map[string]*table, len(to))
+	for _, table := range to {
+		toMap[table.name] = table
+	}
+
+	for _, table := range to {
+		old, ok := fromMap[table.name]
+		if !ok {
+			for _, name := range table.renamedFrom {
+				if _, ok := toMap[name]; ok {
+					continue
+				}
+				if t, ok := fromMap[name]; ok {
+					old = t
+					r.tables[table.name] = name
+					break
+				}
+			}
+		}
+		if old == nil {
+			if len(table.renamedFrom) > 0 && len(from) > 0 {
+				return nil, fmt.Errorf("myddlmaker: table %q: renamed from unknown table: %q", table.name, table.renamedFrom)
+			}
+			continue
+		}
+
+		columns, err := findColumnRenames(old, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) > 0 {
+			r.columns[table.name] = columns
+		}
+	}
+	return r, nil
+}
+
+func findColumnRenames(from, to *table) (map[string]string, error) {
+	ret := map[string]string{}
+	fromColumns := make(map[string]*column, len(from.columns))
+	for _, col := range from.columns {
+		fromColumns[col.name] = col
+	}
+	toColumns := make(map[string]*column, len(to.columns))
+	for _, col := range to.columns {
+		toColumns[col.name] = col
+	}
+
+	for _, col := range to.columns {
+		if _, ok := fromColumns[col.name]; ok || len(col.renamedFrom) == 0 {
+			continue
+		}
+		found := false
+		for _, name := range col.renamedFrom {
+			if _, ok := toColumns[name]; ok {
+				continue
+			}
+			if _, ok := fromColumns[name]; ok {
+				ret[col.name] = name
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("myddlmaker: table %q, column %q: renamed from unknown column: %q", to.name, col.name, col.renamedFrom)
+		}
+	}
+	return ret, nil
+}
+
+// inverse returns the renames that revert r.
+func (r *renames) inverse() *renames {
+	ret := &renames{
+		tables:  make(map[string]string, len(r.tables)),
+		columns: make(map[string]map[string]string, len(r.columns)),
+	}
+	for newName, oldName := range r.tables {
+		ret.tables[oldName] = newName
+	}
+	for table, columns := range r.columns {
+		if oldName, ok := r.tables[table]; ok {
+			table = oldName
+		}
+		inv := make(map[string]string, len(columns))
+		for newName, oldName := range columns {
+			inv[oldName] = newName
+		}
+		ret.columns[table] = inv
+	}
+	return ret
+}
+
+// apply renames the tables and the columns in from.
+// It returns the RENAME statements and the renamed copies of the tables.
+func (r *renames) apply(from []*table) ([]*change, []*table) {
+	// maps the old names to the new names.
+	tableNames := make(map[string]string, len(r.tables))
+	for newName, oldName := range r.tables {
+		tableNames[oldName] = newName
+	}
+	columnNames := make(map[string]map[string]string, len(r.columns))
+	for table, columns := range r.columns {
+		inv := make(map[string]string, len(columns))
+		for newName, oldName := range columns {
+			inv[oldName] = newName
+		}
+		columnNames[table] = inv
+	}
+	renameTable := func(name string) string {
+		if newName, ok := tableNames[name]; ok {
+			return newName
+		}
+		return name
+	}
+	renameColumns := func(table string, columns []string) []string {
+		names, ok := columnNames[table]
+		if !ok {
+			return columns
+		}
+		ret := make([]string, len(columns))
+		for i, col := range columns {
+			if newName, ok := names[col]; ok {
+				col = newName
+			}
+			ret[i] = col
+		}
+		return ret
+	}
+	renameKeyParts := func(table string, parts []*keyPart) []*keyPart {
+		names, ok := columnNames[table]
+		if !ok {
+			return parts
+		}
+		ret := make([]*keyPart, len(parts))
+		for i, part := range parts {
+			if newName, ok := names[part.column]; ok && part.column != "" {
+				p := *part // shallow copy
+				p.column = newName
+				part = &p
+			}
+			ret[i] = part
+		}
+		return ret
+	}
+
+	var changes []*change
+	ret := make([]*table, 0, len(from))
+	for _, t := range from {
+		tmp := *t // shallow copy
+		tmp.name = renameTable(t.name)
+		if tmp.name != t.name {
+			changes = append(changes, &change{
+				kind:  changeRenameTable,
+				table: tmp.name,
+				sql:   "RENAME TABLE " + quote(t.name) + " TO " + quote(tmp.name),
+			})
+		}
+
+		names := columnNames[tmp.name]
+		tmp.columns = make([]*column, 0, len(t.columns))
+		for _, col := range t.columns {
+			if newName, ok := names[col.name]; ok {
+				changes = append(changes, &change{
+					kind:  changeRenameColumn,
+					table: tmp.name,
+					name:  newName,
+					sql:   "RENAME COLUMN " + quote(col.name) + " TO " + quote(newName),
+				})
+				c := *col // shallow copy
+				c.name = newName
+				col = &c
+			}
+			tmp.columns = append(tmp.columns, col)
+		}
+
+		if t.primaryKey != nil {
+			pk := *t.primaryKey // shallow copy
+			pk.columns = renameColumns(tmp.name, t.primaryKey.columns)
+			tmp.primaryKey = &pk
+		}
+		tmp.indexes = make([]*Index, 0, len(t.indexes))
+		for _, idx := range t.indexes {
+			i := *idx // shallow copy
+			i.keyParts = renameKeyParts(tmp.name, idx.keyParts)
+			tmp.indexes = append(tmp.indexes, &i)
+		}
+		tmp.uniqueIndexes = make([]*UniqueIndex, 0, len(t.uniqueIndexes))
+		for _, idx := range t.uniqueIndexes {
+			i := *idx // shallow copy
+			i.keyParts = renameKeyParts(tmp.name, idx.keyParts)
+			tmp.uniqueIndexes = append(tmp.uniqueIndexes, &i)
+		}
+		tmp.fullTextIndexes = make([]*FullTextIndex, 0, len(t.fullTextIndexes))
+		for _, idx := range t.fullTextIndexes {
+			i := *idx // shallow copy
+			i.column = renameColumns(tmp.name, []string{idx.column})[0]
+			tmp.fullTextIndexes = append(tmp.fullTextIndexes, &i)
+		}
+		tmp.spatialIndexes = make([]*SpatialIndex, 0, len(t.spatialIndexes))
+		for _, idx := range t.spatialIndexes {
+			i := *idx // shallow copy
+			i.column = renameColumns(tmp.name, []string{idx.column})[0]
+			tmp.spatialIndexes = append(tmp.spatialIndexes, &i)
+		}
+		tmp.multiValuedIndexes = make([]*MultiValuedIndex, 0, len(t.multiValuedIndexes))
+		for _, idx := range t.multiValuedIndexes {
+			i := *idx // shallow copy
+			i.column = renameColumns(tmp.name, []string{idx.column})[0]
+			tmp.multiValuedIndexes = append(tmp.multiValuedIndexes, &i)
+		}
+
+		// MySQL updates the foreign keys that refer to the renamed tables and columns.
+		tmp.foreignKeys = make([]*ForeignKey, 0, len(t.foreignKeys))
+		for _, fk := range t.foreignKeys {
+			key := *fk // shallow copy
+			key.columns = renameColumns(tmp.name, fk.columns)
+			key.table = renameTable(fk.table)
+			key.references = renameColumns(key.table, fk.references)
+			tmp.foreignKeys = append(tmp.foreignKeys, &key)
+		}
+
+		ret = append(ret, &tmp)
+	}
+	return changes, ret
+}
@@ -0,0 +1,540 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	gotoken "go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateStructs writes Go source code that defines the tables in the schema as Go structs.
+// It is the reverse of GenerateFile,
+// and GenerateFile with the generated structs emits the same CREATE TABLE statements.
+func GenerateStructs(w io.Writer, packageName string, schema *Schema) error {
+	g := &structGenerator{
+		imports: map[string]bool{
+			"github.com/shogo82148/myddlmaker": true,
+		},
+	}
+	for _, table := range schema.tables {
+		if err := g.generateStruct(table); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", packageName)
+	g.generateImports(&buf)
+	buf.Write(g.body.Bytes())
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+type structGenerator struct {
+	imports map[string]bool
+	body    bytes.Buffer
+}
+
+func (g *structGenerator) generateImports(w io.Writer) {
+	var std, others []string
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(others)
+
+	io.WriteString(w, "import (\n")
+	for _, path := range std {
+		fmt.Fprintf(w, "%q\n", path)
+	}
+	if len(std) > 0 && len(others) > 0 {
+		io.WriteString(w, "\n")
+	}
+	for _, path := range others {
+		fmt.Fprintf(w, "%q\n", path)
+	}
+	io.WriteString(w, ")\n\n")
+}
+
+func (g *structGenerator) generateStruct(table *table) error {
+	w := &g.body
+	if !gotoken.IsIdentifier(table.rawName) {
+		return fmt.Errorf("myddlmaker: table %q: %q is not a valid Go identifier", table.name, table.rawName)
+	}
+
+	fmt.Fprintf(w, "type %s struct {\n", table.rawName)
+	for _, col := range table.columns {
+		if !gotoken.IsIdentifier(col.rawName) {
+			return fmt.Errorf("myddlmaker: table %q, column %q: %q is not a valid Go identifier", table.name, col.name, col.rawName)
+		}
+		typ, opts, err := g.goType(col)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: table %q, column %q: %w", table.name, col.name, err)
+		}
+		fmt.Fprintf(w, "%s %s", col.rawName, typ)
+
+		var name string
+		if camelToSnake(col.rawName) != col.name {
+			name = col.name
+		}
+		if name != "" || len(opts) > 0 {
+			tag := StructTagName + ":" + strconv.Quote(strings.Join(append([]string{name}, opts...), ","))
+			if strings.Contains(tag, "`") {
+				fmt.Fprintf(w, " %q", tag)
+			} else {
+				fmt.Fprintf(w, " `%s`", tag)
+			}
+		}
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, "}\n\n")
+
+	if camelToSnake(table.rawName) != table.name {
+		fmt.Fprintf(w, "func (*%s) Table() string {\n", table.rawName)
+		fmt.Fprintf(w, "return %q\n", table.name)
+		io.WriteString(w, "}\n\n")
+	}
+
+	fmt.Fprintf(w, "func (*%s) PrimaryKey() *myddlmaker.PrimaryKey {\n", table.rawName)
+	fmt.Fprintf(w, "return myddlmaker.NewPrimaryKey(%s)", quoteGoStrings(table.primaryKey.columns))
+	switch table.primaryKey.clustered {
+	case "CLUSTERED":
+		io.WriteString(w, ".Clustered()")
+	case "NONCLUSTERED":
+		io.WriteString(w, ".NonClustered()")
+	}
+	io.WriteString(w, "\n")
+	io.WriteString(w, "}\n\n")
+
+	if len(table.indexes) > 0 {
+		fmt.Fprintf(w, "func (*%s) Indexes() []*myddlmaker.Index {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.Index{\n")
+		for _, idx := range table.indexes {
+			fmt.Fprintf(w, "myddlmaker.NewIndex(%q, %s)", idx.name, quoteGoStrings(keyPartStrings(idx.keyParts)))
+			if idx.comment != "" {
+				fmt.Fprintf(w, ".Comment(%q)", idx.comment)
+			}
+			if idx.invisible {
+				io.WriteString(w, ".Invisible()")
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	if len(table.uniqueIndexes) > 0 {
+		fmt.Fprintf(w, "func (*%s) UniqueIndexes() []*myddlmaker.UniqueIndex {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.UniqueIndex{\n")
+		for _, idx := range table.uniqueIndexes {
+			fmt.Fprintf(w, "myddlmaker.NewUniqueIndex(%q, %s)", idx.name, quoteGoStrings(keyPartStrings(idx.keyParts)))
+			if idx.comment != "" {
+				fmt.Fprintf(w, ".Comment(%q)", idx.comment)
+			}
+			if idx.invisible {
+				io.WriteString(w, ".Invisible()")
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	if len(table.fullTextIndexes) > 0 {
+		fmt.Fprintf(w, "func (*%s) FullTextIndexes() []*myddlmaker.FullTextIndex {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.FullTextIndex{\n")
+		for _, idx := range table.fullTextIndexes {
+			fmt.Fprintf(w, "myddlmaker.NewFullTextIndex(%q, %q)", idx.name, idx.column)
+			if idx.parser != "" {
+				fmt.Fprintf(w, ".WithParser(%q)", idx.parser)
+			}
+			if idx.comment != "" {
+				fmt.Fprintf(w, ".Comment(%q)", idx.comment)
+			}
+			if idx.invisible {
+				io.WriteString(w, ".Invisible()")
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	if len(table.spatialIndexes) > 0 {
+		fmt.Fprintf(w, "func (*%s) SpatialIndexes() []*myddlmaker.SpatialIndex {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.SpatialIndex{\n")
+		for _, idx := range table.spatialIndexes {
+			fmt.Fprintf(w, "myddlmaker.NewSpatialIndex(%q, %q)", idx.name, idx.column)
+			if idx.comment != "" {
+				fmt.Fprintf(w, ".Comment(%q)", idx.comment)
+			}
+			if idx.invisible {
+				io.WriteString(w, ".Invisible()")
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	if len(table.foreignKeys) > 0 {
+		fmt.Fprintf(w, "func (*%s) ForeignKeys() []*myddlmaker.ForeignKey {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.ForeignKey{\n")
+		for _, fk := range table.foreignKeys {
+			fmt.Fprintf(
+				w, "myddlmaker.NewForeignKey(%q, []string{%s}, %q, []string{%s})",
+				fk.name, quoteGoStrings(fk.columns), fk.table, quoteGoStrings(fk.references),
+			)
+			if fk.onDelete != "" {
+				fmt.Fprintf(w, ".OnDelete(%s)", goForeignKeyOption(fk.onDelete))
+			}
+			if fk.onUpdate != "" {
+				fmt.Fprintf(w, ".OnUpdate(%s)", goForeignKeyOption(fk.onUpdate))
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	if len(table.checks) > 0 {
+		fmt.Fprintf(w, "func (*%s) Checks() []*myddlmaker.CheckConstraint {\n", table.rawName)
+		io.WriteString(w, "return []*myddlmaker.CheckConstraint{\n")
+		for _, chk := range table.checks {
+			fmt.Fprintf(w, "myddlmaker.NewCheck(%q, %q)", chk.name, chk.expr)
+			if chk.notEnforced {
+				io.WriteString(w, ".NotEnforced()")
+			}
+			io.WriteString(w, ",\n")
+		}
+		io.WriteString(w, "}\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	var opts []string
+	if table.engine != "" {
+		opts = append(opts, fmt.Sprintf("Engine(%q)", table.engine))
+	}
+	if table.charset != "" {
+		opts = append(opts, fmt.Sprintf("Charset(%q)", table.charset))
+	}
+	if table.collate != "" {
+		opts = append(opts, fmt.Sprintf("Collate(%q)", table.collate))
+	}
+	if table.rowFormat != "" {
+		opts = append(opts, fmt.Sprintf("RowFormat(%q)", table.rowFormat))
+	}
+	if table.keyBlockSize > 0 {
+		opts = append(opts, fmt.Sprintf("KeyBlockSize(%d)", table.keyBlockSize))
+	}
+	if table.autoIncrement > 0 {
+		opts = append(opts, fmt.Sprintf("AutoIncrement(%d)", table.autoIncrement))
+	}
+	switch table.statsPersistent {
+	case "1":
+		opts = append(opts, "StatsPersistent(true)")
+	case "0":
+		opts = append(opts, "StatsPersistent(false)")
+	}
+	if table.comment != "" {
+		opts = append(opts, fmt.Sprintf("Comment(%q)", table.comment))
+	}
+	if table.shardRowIDBits > 0 {
+		opts = append(opts, fmt.Sprintf("ShardRowIDBits(%d)", table.shardRowIDBits))
+	}
+	if len(opts) > 0 {
+		fmt.Fprintf(w, "func (*%s) TableOptions() *myddlmaker.TableOptions {\n", table.rawName)
+		fmt.Fprintf(w, "return myddlmaker.NewTableOptions().\n%s\n", strings.Join(opts, ".\n"))
+		io.WriteString(w, "}\n\n")
+	}
+
+	if p := table.partitions; p != nil {
+		fmt.Fprintf(w, "func (*%s) Partitions() *myddlmaker.Partitions {\n", table.rawName)
+		name := p.typ[:1] + strings.ToLower(p.typ[1:]) // e.g. RANGE -> Range
+		switch {
+		case p.expr != "":
+			fmt.Fprintf(w, "return myddlmaker.New%sPartitions(%q)", name, p.expr)
+		case p.typ == "KEY":
+			fmt.Fprintf(w, "return myddlmaker.NewKeyPartitions(%s)", quoteGoStrings(p.columns))
+		default:
+			fmt.Fprintf(w, "return myddlmaker.New%sColumnsPartitions(%s)", name, quoteGoStrings(p.columns))
+		}
+		if p.linear {
+			io.WriteString(w, ".Linear()")
+		}
+		if p.count > 0 {
+			fmt.Fprintf(w, ".Count(%d)", p.count)
+		}
+		if len(p.definitions) > 0 {
+			io.WriteString(w, ".Add(\n")
+			for _, def := range p.definitions {
+				fmt.Fprintf(w, "myddlmaker.NewPartition(%q)", def.name)
+				switch def.op {
+				case "LESS THAN":
+					fmt.Fprintf(w, ".LessThan(%s)", quoteGoStrings(def.values))
+				case "IN":
+					fmt.Fprintf(w, ".In(%s)", quoteGoStrings(def.values))
+				}
+				if def.comment != "" {
+					fmt.Fprintf(w, ".Comment(%q)", def.comment)
+				}
+				io.WriteString(w, ",\n")
+			}
+			io.WriteString(w, ")")
+		}
+		io.WriteString(w, "\n")
+		io.WriteString(w, "}\n\n")
+	}
+
+	return nil
+}
+
+// goType returns the Go type of the column and the options of the struct tag.
+// newColumn maps the returned type and options into the same column.
+func (g *structGenerator) goType(col *column) (string, []string, error) {
+	var typ string
+	var size int             // the default size of typ
+	var override bool        // typ can't represent the column type, use the type option.
+	var precision, scale int // the default precision and scale of typ
+
+	switch col.typ {
+	case "TINYINT":
+		switch {
+		case col.size == 1 && !col.unsigned:
+			typ, size = "bool", 1
+			if col.null {
+				typ = "sql.NullBool"
+			}
+		case col.unsigned:
+			typ = "uint8"
+			if col.null {
+				typ = "sql.NullByte"
+			}
+		default:
+			typ = "int8"
+		}
+	case "SMALLINT":
+		typ = "int16"
+		if col.null && !col.unsigned {
+			typ = "sql.NullInt16"
+		}
+	case "INTEGER":
+		typ = "int32"
+		if col.null && !col.unsigned {
+			typ = "sql.NullInt32"
+		}
+	case "BIGINT":
+		typ = "int64"
+		if col.null && !col.unsigned {
+			typ = "sql.NullInt64"
+		}
+	case "FLOAT":
+		typ = "float32"
+	case "DOUBLE":
+		typ = "float64"
+		if col.null {
+			typ = "sql.NullFloat64"
+		}
+	case "VARCHAR":
+		typ, size = "string", 191
+		if col.null {
+			typ = "sql.NullString"
+		}
+	case "VARBINARY":
+		typ, size = "[]byte", 767
+	case "BINARY":
+		typ, size = fmt.Sprintf("[%d]byte", col.size), col.size
+	case "DECIMAL":
+		typ = "myddlmaker.Decimal"
+		precision, scale = 10, 0
+	case "JSON":
+		typ = "json.RawMessage"
+	case "DATETIME":
+		typ, size = "time.Time", 6
+		if col.null {
+			typ = "sql.NullTime"
+		}
+	default:
+		override = true
+		typ = goTypeForSQLType(col.typ, col.null)
+	}
+	if col.unsigned && (col.typ == "FLOAT" || col.typ == "DOUBLE" || col.typ == "DECIMAL") {
+		// Go has no unsigned floating-point types, keep UNSIGNED by the type option.
+		override = true
+	}
+
+	if strings.HasPrefix(typ, "int") && col.unsigned {
+		typ = "u" + typ
+	}
+	if col.null && !strings.HasPrefix(typ, "sql.") && !strings.HasPrefix(typ, "[]") && typ != "json.RawMessage" {
+		// nullable types without sql.Null* counterparts.
+		typ = "*" + typ
+	}
+
+	// imports
+	switch {
+	case strings.Contains(typ, "sql."):
+		g.imports["database/sql"] = true
+	case strings.Contains(typ, "json."):
+		g.imports["encoding/json"] = true
+	case strings.Contains(typ, "time."):
+		g.imports["time"] = true
+	}
+
+	var opts []string
+	if col.null {
+		opts = append(opts, "null")
+	}
+	if col.autoIncr {
+		opts = append(opts, "auto")
+	}
+	if col.autoRandom {
+		if col.shardBits > 0 {
+			opts = append(opts, "auto_random="+strconv.Itoa(col.shardBits))
+		} else {
+			opts = append(opts, "auto_random")
+		}
+	}
+	if col.invisible {
+		opts = append(opts, "invisible")
+	}
+	if col.generated != "" {
+		opts = append(opts, "as=("+col.generated+")")
+		if col.stored {
+			opts = append(opts, "stored")
+		}
+	}
+	if override {
+		t := col.typ
+		if t == "ENUM" || t == "SET" {
+			t += formatValues(col.values)
+		}
+		if col.precision > 0 {
+			t += fmt.Sprintf("(%d,%d)", col.precision, col.scale)
+		}
+		if col.unsigned {
+			t += " UNSIGNED"
+		}
+		opts = append(opts, "type="+t)
+		size = 0
+	}
+	if col.size != size {
+		opts = append(opts, "size="+strconv.Itoa(col.size))
+	}
+	if !override && col.precision != precision {
+		opts = append(opts, "precision="+strconv.Itoa(col.precision))
+	}
+	if !override && col.scale != scale {
+		opts = append(opts, "scale="+strconv.Itoa(col.scale))
+	}
+	if col.srid != 0 {
+		opts = append(opts, "srid="+strconv.Itoa(col.srid))
+	}
+	// the columns that have the current timestamp are owned by the database.
+	def := col.def
+	switch {
+	case col.onUpdate == "" && isCurrentTimestamp(col.def, col.size):
+		opts = append(opts, "auto_now_add")
+		def = ""
+	case isCurrentTimestamp(col.def, col.size) && isCurrentTimestamp(col.onUpdate, col.size):
+		opts = append(opts, "auto_now")
+		def = ""
+	case col.onUpdate != "":
+		return "", nil, fmt.Errorf("ON UPDATE %s is supported only with DEFAULT %s", col.onUpdate, currentTimestamp(col.size))
+	}
+	if def != "" {
+		opts = append(opts, "default="+def)
+	}
+	if col.charset != "" {
+		opts = append(opts, "charset="+col.charset)
+	}
+	if col.collate != "" {
+		opts = append(opts, "collate="+col.collate)
+	}
+	if col.comment != "" {
+		opts = append(opts, "comment="+col.comment)
+	}
+
+	for _, opt := range opts {
+		if _, _, found := cutComma(opt); found {
+			return "", nil, fmt.Errorf("the struct tag option %q contains a comma", opt)
+		}
+	}
+	return typ, opts, nil
+}
+
+// isCurrentTimestamp reports whether expr is the current timestamp with the fractional seconds precision fsp.
+func isCurrentTimestamp(expr string, fsp int) bool {
+	v, ok := parseCurrentTimestamp(expr)
+	return ok && v == fsp
+}
+
+// goTypeForSQLType returns the Go type for the SQL types that newColumn doesn't generate.
+func goTypeForSQLType(typ string, null bool) string {
+	name, _, _ := strings.Cut(typ, "(")
+	switch name {
+	case "MEDIUMINT", "YEAR":
+		if null {
+			return "sql.NullInt32"
+		}
+		return "int32"
+	case "DECIMAL", "NUMERIC", "DOUBLE", "FLOAT", "REAL":
+		if null {
+			return "sql.NullFloat64"
+		}
+		return "float64"
+	case "DATE", "TIMESTAMP":
+		if null {
+			return "sql.NullTime"
+		}
+		return "time.Time"
+	case "BIT", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB":
+		return "[]byte"
+	}
+	if null {
+		return "sql.NullString"
+	}
+	return "string"
+}
+
+// keyPartStrings returns the key parts in the format that NewIndex accepts.
+func keyPartStrings(parts []*keyPart) []string {
+	ret := make([]string, len(parts))
+	for i, part := range parts {
+		ret[i] = part.String()
+	}
+	return ret
+}
+
+func goForeignKeyOption(opt ForeignKeyOption) string {
+	switch opt {
+	case ForeignKeyOptionCascade:
+		return "myddlmaker.ForeignKeyOptionCascade"
+	case ForeignKeyOptionSetNull:
+		return "myddlmaker.ForeignKeyOptionSetNull"
+	case ForeignKeyOptionRestrict:
+		return "myddlmaker.ForeignKeyOptionRestrict"
+	}
+	return fmt.Sprintf("myddlmaker.ForeignKeyOption(%q)", string(opt))
+}
+
+func quoteGoStrings(s []string) string {
+	ret := make([]string, len(s))
+	for i, v := range s {
+		ret[i] = strconv.Quote(v)
+	}
+	return strings.Join(ret, ", ")
+}
@@ -0,0 +1,58 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestGenerateStructs checks that testdata/reverse/schema.go is generated from testdata/reverse/expected.sql.
+func TestGenerateStructs(t *testing.T) {
+	f, err := os.Open("testdata/reverse/expected.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	schema, err := ParseSQL(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := GenerateStructs(&buf, "schema", schema); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("testdata/reverse/schema.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+		t.Errorf("source code is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestGenerateStructs_Error(t *testing.T) {
+	ddl := "CREATE TABLE `foo` (\n" +
+		"  `id` INTEGER NOT NULL COMMENT 'a comment, with a comma',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");"
+	schema, err := ParseSQL(strings.NewReader(ddl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = GenerateStructs(&buf, "schema", schema)
+	if err == nil {
+		t.Fatal("want some error, but not")
+	}
+	want := `myddlmaker: table "foo", column "id": the struct tag option "comment=a comment, with a comma" contains a comma`
+	if err.Error() != want {
+		t.Errorf("unexpected error: got %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,262 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// changeSafety is the risk of a change.
+type changeSafety int
+
+const (
+	// changeSafe doesn't lose data, and it doesn't block queries for long.
+	changeSafe changeSafety = iota
+
+	// changeLocking doesn't lose data, but it rebuilds the table or blocks writes to the table.
+	changeLocking
+
+	// changeDataLoss may lose data.
+	changeDataLoss
+)
+
+func (s changeSafety) String() string {
+	switch s {
+	case changeSafe:
+		return "safe"
+	case changeLocking:
+		return "locking"
+	case changeDataLoss:
+		return "data loss"
+	}
+	return fmt.Sprintf("changeSafety(%d)", int(s))
+}
+
+// safety classifies the change, and returns the reason.
+func (m *Maker) safety(c *change) (changeSafety, string) {
+	switch c.kind {
+	case changeDropTable:
+		return changeDataLoss, "drops the table " + quote(c.table)
+	case changeDropSequence:
+		return changeDataLoss, "drops the sequence " + quote(c.table)
+	case changeDropColumn:
+		return changeDataLoss, "drops the column " + quote(c.table) + "." + quote(c.name)
+	case changeModifyColumn:
+		if reason := columnDataLoss(c.oldTable, c.newTable, c.oldColumn, c.newColumn, m.config.DB); reason != "" {
+			return changeDataLoss, reason
+		}
+		if ddl := m.onlineDDL(c); ddl.algorithm == "COPY" {
+			return changeLocking, "copies the table " + quote(c.table)
+		} else if ddl.rebuild {
+			return changeLocking, "rebuilds the table " + quote(c.table)
+		}
+	case changeAddColumn:
+		if ddl := m.onlineDDL(c); ddl.algorithm == "COPY" {
+			return changeLocking, "copies the table " + quote(c.table)
+		} else if c.noInstant {
+			return changeLocking, "rebuilds the table " + quote(c.table)
+		}
+	case changeTableOptions:
+		if ddl := m.onlineDDL(c); ddl.algorithm == "COPY" {
+			return changeLocking, "copies the table " + quote(c.table)
+		} else if ddl.rebuild {
+			return changeLocking, "rebuilds the table " + quote(c.table)
+		}
+	case changeDropIndex, changeAddIndex:
+		switch ddl := m.onlineDDL(c); {
+		case ddl.algorithm == "COPY":
+			return changeLocking, "copies the table " + quote(c.table)
+		case ddl.lock == "SHARED":
+			return changeLocking, "blocks writes to the table " + quote(c.table)
+		case ddl.rebuild:
+			return changeLocking, "rebuilds the table " + quote(c.table)
+		}
+	case changeAddForeignKey:
+		// the table is copied if foreign_key_checks is enabled.
+		return changeLocking, "copies the table " + quote(c.table) + " to check the constraint"
+	case changeAddCheck:
+		return changeLocking, "copies the table " + quote(c.table) + " to check the constraint"
+	case changePartitionBy, changeRemovePartitioning:
+		return changeLocking, "copies the table " + quote(c.table)
+	}
+	return changeSafe, ""
+}
+
+var integerRanks = map[string]int{
+	"TINYINT":   1,
+	"SMALLINT":  2,
+	"MEDIUMINT": 3,
+	"INTEGER":   4,
+	"BIGINT":    5,
+}
+
+// wideningTypes is the types that can be changed to the types of higher ranks without losing data.
+var wideningTypes = []map[string]int{
+	integerRanks,
+	{
+		"CHAR":       1,
+		"VARCHAR":    2,
+		"TEXT":       3,
+		"MEDIUMTEXT": 4,
+		"LONGTEXT":   5,
+	},
+	{
+		"BINARY":     1,
+		"VARBINARY":  2,
+		"BLOB":       3,
+		"MEDIUMBLOB": 4,
+		"LONGBLOB":   5,
+	},
+	{
+		"FLOAT":  1,
+		"DOUBLE": 2,
+	},
+}
+
+// columnDataLoss reports why changing the column from old in the table from to new in the table to loses data.
+// It returns an empty string if the change is safe.
+// It is conservative, changing types to unknown types are considered to lose data.
+func columnDataLoss(from, to *table, old, new *column, db *DBConfig) string {
+	name := quote(old.name)
+	if old.typ != new.typ {
+		widening := false
+		for _, ranks := range wideningTypes {
+			o, ok1 := ranks[old.typ]
+			n, ok2 := ranks[new.typ]
+			if ok1 && ok2 && o < n {
+				widening = true
+				break
+			}
+		}
+		if !widening {
+			return fmt.Sprintf("changes the type of %s from %s to %s", name, old.typ, new.typ)
+		}
+	}
+	if _, ok := integerRanks[new.typ]; !ok && new.size < old.size && (old.typ == new.typ || new.size > 0) {
+		// the display width of integer types doesn't matter.
+		return fmt.Sprintf("narrows the size of %s from %d to %d", name, old.size, new.size)
+	}
+
+	if old.typ == new.typ && old.precision > 0 && new.precision > 0 {
+		// the number of digits before and after the decimal point.
+		if new.precision-new.scale < old.precision-old.scale || new.scale < old.scale {
+			return fmt.Sprintf("narrows the precision of %s from (%d,%d) to (%d,%d)", name, old.precision, old.scale, new.precision, new.scale)
+		}
+	}
+	if old.typ == new.typ && (new.typ == "ENUM" || new.typ == "SET") {
+		for _, v := range old.values {
+			if !contains(new.values, v) {
+				return fmt.Sprintf("removes the value %s from %s", stringQuote(v), name)
+			}
+		}
+	}
+
+	if !old.unsigned && new.unsigned {
+		return fmt.Sprintf("makes %s UNSIGNED", name)
+	}
+	if old.unsigned && !new.unsigned && old.typ == new.typ {
+		return fmt.Sprintf("makes %s SIGNED", name)
+	}
+	if old.null && !new.null {
+		return fmt.Sprintf("makes %s NOT NULL", name)
+	}
+	// the columns may inherit the character sets from the tables or the database.
+	oldCharset, _ := columnCharset(from, old, db)
+	newCharset, _ := columnCharset(to, new, db)
+	if oldCharset != "" && newCharset != "" && oldCharset != newCharset {
+		return fmt.Sprintf("changes the character set of %s from %s to %s", name, oldCharset, newCharset)
+	}
+	return ""
+}
+
+// allowDestructive reports whether Config.AllowDestructive allows the change.
+func (m *Maker) allowDestructive(c *change) bool {
+	for _, name := range m.config.AllowDestructive {
+		if name == c.table {
+			return true
+		}
+		if c.kind != changeDropTable && name == c.table+"."+c.name {
+			return true
+		}
+	}
+	return false
+}
+
+// DestructiveChangeError is returned when the changes lose data,
+// and they are not allowed by Config.AllowDestructive.
+type DestructiveChangeError struct {
+	// Changes are the descriptions of the changes.
+	Changes []string
+}
+
+func (e *DestructiveChangeError) Error() string {
+	var buf strings.Builder
+	buf.WriteString("myddlmaker: destructive changes are not allowed:")
+	for _, c := range e.Changes {
+		buf.WriteString("\n    ")
+		buf.WriteString(c)
+	}
+	return buf.String()
+}
+
+// checkDestructive returns an error if some changes lose data,
+// and they are not allowed by Config.AllowDestructive.
+func (m *Maker) checkDestructive(changes []*change) error {
+	var errs []string
+	for _, c := range changes {
+		safety, reason := m.safety(c)
+		if safety != changeDataLoss || m.allowDestructive(c) {
+			continue
+		}
+		errs = append(errs, m.statement(c)+"; -- "+reason)
+	}
+	if len(errs) > 0 {
+		return &DestructiveChangeError{
+			Changes: errs,
+		}
+	}
+	return nil
+}
+
+// GenerateReport writes a report of the changes that GenerateDiff makes.
+// It classifies the changes into safe, locking and data loss,
+// and it shows the algorithms and the lock levels that MySQL uses for them.
+// Unlike GenerateDiff, it doesn't fail even if the changes are not allowed by Config.AllowDestructive.
+func (m *Maker) GenerateReport(w io.Writer, old *Schema) error {
+	var buf bytes.Buffer
+	changes, err := m.diffSchema(old)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		safety, reason := m.safety(c)
+		fmt.Fprintf(&buf, "[%s] %s;\n", safety, m.statement(c))
+		if ddl := m.onlineDDL(c); ddl.algorithm != "" {
+			fmt.Fprintf(&buf, "    algorithm: %s, lock: %s", ddl.algorithm, ddl.lock)
+			if ddl.rebuild {
+				buf.WriteString(", rebuilds the table")
+			}
+			buf.WriteString("\n")
+		}
+		if reason == "" {
+			continue
+		}
+		buf.WriteString("    ")
+		buf.WriteString(reason)
+		if safety == changeDataLoss {
+			if m.allowDestructive(c) {
+				buf.WriteString(" (allowed)")
+			} else {
+				buf.WriteString(" (NOT allowed)")
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package myddlmaker
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestColumnDataLoss(t *testing.T) {
+	tests := []struct {
+		from *table
+		to   *table
+		db   *DBConfig
+		old  *column
+		new  *column
+		want string
+	}{
+		{
+			old:  &column{name: "id", typ: "INTEGER"},
+			new:  &column{name: "id", typ: "BIGINT"},
+			want: "",
+		},
+		{
+			old:  &column{name: "id", typ: "BIGINT"},
+			new:  &column{name: "id", typ: "INTEGER"},
+			want: "changes the type of `id` from BIGINT to INTEGER",
+		},
+		{
+			old:  &column{name: "id", typ: "INTEGER", unsigned: true},
+			new:  &column{name: "id", typ: "BIGINT"},
+			want: "",
+		},
+		{
+			old:  &column{name: "id", typ: "INTEGER"},
+			new:  &column{name: "id", typ: "INTEGER", unsigned: true},
+			want: "makes `id` UNSIGNED",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 191},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255},
+			want: "",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "VARCHAR", size: 191},
+			want: "narrows the size of `name` from 255 to 191",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "TEXT"},
+			want: "",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "INTEGER"},
+			want: "changes the type of `name` from VARCHAR to INTEGER",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255, null: true},
+			want: "",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255, null: true},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255},
+			want: "makes `name` NOT NULL",
+		},
+		{
+			old:  &column{name: "name", typ: "VARCHAR", size: 255, charset: "utf8mb4"},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255, charset: "latin1"},
+			want: "changes the character set of `name` from utf8mb4 to latin1",
+		},
+		{
+			// the column inherits the character set from the table.
+			from: &table{charset: "utf8mb4"},
+			to:   &table{charset: "utf8mb4"},
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255, charset: "latin1"},
+			want: "changes the character set of `name` from utf8mb4 to latin1",
+		},
+		{
+			// the column inherits the character set from the database.
+			db:   &DBConfig{Charset: "utf8mb4"},
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255, charset: "latin1"},
+			want: "changes the character set of `name` from utf8mb4 to latin1",
+		},
+		{
+			from: &table{charset: "utf8mb4"},
+			to:   &table{charset: "utf8mb4"},
+			old:  &column{name: "name", typ: "VARCHAR", size: 255},
+			new:  &column{name: "name", typ: "VARCHAR", size: 255, charset: "utf8mb4"},
+			want: "",
+		},
+		{
+			old:  &column{name: "price", typ: "DECIMAL", precision: 9, scale: 2},
+			new:  &column{name: "price", typ: "DECIMAL", precision: 12, scale: 4},
+			want: "",
+		},
+		{
+			old:  &column{name: "price", typ: "DECIMAL", precision: 9, scale: 2},
+			new:  &column{name: "price", typ: "DECIMAL", precision: 9, scale: 4},
+			want: "narrows the precision of `price` from (9,2) to (9,4)",
+		},
+		{
+			old:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive"}},
+			new:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive", "deleted"}},
+			want: "",
+		},
+		{
+			old:  &column{name: "status", typ: "ENUM", values: []string{"active", "inactive"}},
+			new:  &column{name: "status", typ: "ENUM", values: []string{"active"}},
+			want: "removes the value 'inactive' from `status`",
+		},
+	}
+
+	for _, tt := range tests {
+		from, to := tt.from, tt.to
+		if from == nil {
+			from = &table{}
+		}
+		if to == nil {
+			to = &table{}
+		}
+		got := columnDataLoss(from, to, tt.old, tt.new, tt.db)
+		if got != tt.want {
+			t.Errorf("%v -> %v: got %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestMaker_GenerateReport(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&DiffUser2{}, &DiffEntry1{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{
+		AllowDestructive: []string{"user.email"},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&DiffUser1{}, &DiffEntry2{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateReport(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	want := "[safe] ALTER TABLE `entry` DROP FOREIGN KEY `fk_entry_user`;\n" +
+		"    algorithm: INPLACE, lock: NONE\n" +
+		"[safe] ALTER TABLE `user` DROP INDEX `uniq_email`;\n" +
+		"    algorithm: INPLACE, lock: NONE\n" +
+		"[safe] ALTER TABLE `entry` DROP INDEX `idx_user_id`;\n" +
+		"    algorithm: INPLACE, lock: NONE\n" +
+		"[data loss] ALTER TABLE `user` DROP COLUMN `email`;\n" +
+		"    algorithm: INSTANT, lock: DEFAULT\n" +
+		"    drops the column `user`.`email` (allowed)\n" +
+		"[data loss] ALTER TABLE `entry` DROP COLUMN `user_id`;\n" +
+		"    algorithm: INSTANT, lock: DEFAULT\n" +
+		"    drops the column `entry`.`user_id` (NOT allowed)\n" +
+		"[data loss] ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NOT NULL;\n" +
+		"    algorithm: INPLACE, lock: NONE, rebuilds the table\n" +
+		"    makes `age` NOT NULL (NOT allowed)\n" +
+		"[safe] ALTER TABLE `user` ADD INDEX `idx_age` (`age`);\n" +
+		"    algorithm: INPLACE, lock: NONE\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("report is not match: (-want/+got)\n%s", diff)
+	}
+
+	// GenerateDiff refuses the changes that are not allowed.
+	buf.Reset()
+	err = m.GenerateDiff(&buf, schema)
+	var derr *DestructiveChangeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("want DestructiveChangeError, got %v", err)
+	}
+	wantChanges := []string{
+		"ALTER TABLE `entry` DROP COLUMN `user_id`; -- drops the column `entry`.`user_id`",
+		"ALTER TABLE `user` MODIFY COLUMN `age` INTEGER NOT NULL; -- makes `age` NOT NULL",
+	}
+	if diff := cmp.Diff(wantChanges, derr.Changes); diff != "" {
+		t.Errorf("destructive changes are not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateReport_PrimaryKey(t *testing.T) {
+	old, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&DiffLog0{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&DiffLog1{})
+
+	var buf bytes.Buffer
+	if err := m.GenerateReport(&buf, schema); err != nil {
+		t.Fatal(err)
+	}
+	want := "[locking] ALTER TABLE `log` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`, `user_id`);\n" +
+		"    algorithm: INPLACE, lock: NONE, rebuilds the table\n" +
+		"    rebuilds the table `log`\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("report is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+type SafetyUser1 struct {
+	ID   int32
+	Name string
+}
+
+func (*SafetyUser1) Table() string {
+	return "user"
+}
+
+func (*SafetyUser1) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+type SafetyUser2 struct {
+	ID   int32
+	Name string `ddl:",charset=latin1"`
+}
+
+func (*SafetyUser2) Table() string {
+	return "user"
+}
+
+func (*SafetyUser2) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func TestMaker_GenerateDiff_InheritedCharset(t *testing.T) {
+	config := &Config{
+		DB: &DBConfig{
+			Charset: "utf8mb4",
+		},
+	}
+	old, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	old.AddStructs(&SafetyUser1{})
+	schema, err := old.Schema()
+	if err != nil {
+		t.Fatalf("failed to parse the old schema: %v", err)
+	}
+
+	m, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&SafetyUser2{})
+
+	// `name` inherits utf8mb4 from the database.
+	var buf bytes.Buffer
+	err = m.GenerateDiff(&buf, schema)
+	var derr *DestructiveChangeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("want DestructiveChangeError, got %v", err)
+	}
+	wantChanges := []string{
+		"ALTER TABLE `user` MODIFY COLUMN `name` VARCHAR(191) CHARACTER SET latin1 NOT NULL; -- changes the character set of `name` from utf8mb4 to latin1",
+	}
+	if diff := cmp.Diff(wantChanges, derr.Changes); diff != "" {
+		t.Errorf("destructive changes are not match: (-want/+got)\n%s", diff)
+	}
+}
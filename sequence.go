@@ -0,0 +1,210 @@
+package myddlmaker
+
+import (
+	"io"
+	"math"
+	"strconv"
+)
+
+// Sequence is a sequence object of MariaDB and TiDB.
+// https://mariadb.com/kb/en/create-sequence/
+// https://docs.pingcap.com/tidb/stable/sql-statement-create-sequence
+//
+// Add the sequences by [Maker.AddSequences].
+// They are created before the tables, so the tables can use them in the default values.
+//
+//	// CREATE SEQUENCE `seq_order` START WITH 1000 INCREMENT BY 10
+//	m.AddSequences(myddlmaker.NewSequence("seq_order").Start(1000).Increment(10))
+//
+//	type Order struct {
+//		ID int64 `ddl:",default=NEXT VALUE FOR seq_order"`
+//	}
+type Sequence struct {
+	name        string
+	start       int64
+	hasStart    bool
+	increment   int64
+	minValue    int64
+	hasMinValue bool
+	maxValue    int64
+	hasMaxValue bool
+	cache       int64
+	cycle       bool
+}
+
+// NewSequence returns a new sequence.
+func NewSequence(name string) *Sequence {
+	if name == "" {
+		panic("name is missing")
+	}
+	return &Sequence{
+		name: name,
+	}
+}
+
+// Start returns a copy of seq with the first value n.
+func (seq *Sequence) Start(n int64) *Sequence {
+	tmp := *seq // shallow copy
+	tmp.start = n
+	tmp.hasStart = true
+	return &tmp
+}
+
+// Increment returns a copy of seq with the increment n.
+// The sequence counts down if n is negative.
+func (seq *Sequence) Increment(n int64) *Sequence {
+	tmp := *seq // shallow copy
+	tmp.increment = n
+	return &tmp
+}
+
+// MinValue returns a copy of seq with the minimum value n.
+func (seq *Sequence) MinValue(n int64) *Sequence {
+	tmp := *seq // shallow copy
+	tmp.minValue = n
+	tmp.hasMinValue = true
+	return &tmp
+}
+
+// MaxValue returns a copy of seq with the maximum value n.
+func (seq *Sequence) MaxValue(n int64) *Sequence {
+	tmp := *seq // shallow copy
+	tmp.maxValue = n
+	tmp.hasMaxValue = true
+	return &tmp
+}
+
+// Cache returns a copy of seq that caches n values in memory.
+func (seq *Sequence) Cache(n int64) *Sequence {
+	tmp := *seq // shallow copy
+	tmp.cache = n
+	return &tmp
+}
+
+// Cycle returns a copy of seq that restarts from the minimum value after it reaches the maximum value.
+func (seq *Sequence) Cycle() *Sequence {
+	tmp := *seq // shallow copy
+	tmp.cycle = true
+	return &tmp
+}
+
+// AddSequences adds the sequences that the DDL Maker creates.
+// Sequences are supported only by DialectMariaDB and DialectTiDB.
+func (m *Maker) AddSequences(seqs ...*Sequence) {
+	m.sequences = append(m.sequences, seqs...)
+}
+
+// generateSequences writes DROP SEQUENCE and CREATE SEQUENCE statements of the sequences.
+func (m *Maker) generateSequences(w io.Writer) {
+	for _, seq := range m.sequences {
+		if !m.config.SkipDropTable {
+			io.WriteString(w, "DROP SEQUENCE IF EXISTS ")
+			io.WriteString(w, quote(seq.name))
+			io.WriteString(w, ";\n\n")
+		}
+		m.generateCreateSequence(w, seq, m.config.Bootstrap)
+		io.WriteString(w, ";\n\n")
+	}
+}
+
+// generateCreateSequence writes the CREATE SEQUENCE statement of the sequence without the trailing semicolon.
+func (m *Maker) generateCreateSequence(w io.Writer, seq *Sequence, ifNotExists bool) {
+	io.WriteString(w, "CREATE SEQUENCE ")
+	if ifNotExists {
+		io.WriteString(w, "IF NOT EXISTS ")
+	}
+	io.WriteString(w, quote(seq.name))
+	if seq.hasStart {
+		io.WriteString(w, " START WITH ")
+		io.WriteString(w, strconv.FormatInt(seq.start, 10))
+	}
+	if seq.increment != 0 {
+		io.WriteString(w, " INCREMENT BY ")
+		io.WriteString(w, strconv.FormatInt(seq.increment, 10))
+	}
+	if seq.hasMinValue {
+		io.WriteString(w, " MINVALUE ")
+		io.WriteString(w, strconv.FormatInt(seq.minValue, 10))
+	}
+	if seq.hasMaxValue {
+		io.WriteString(w, " MAXVALUE ")
+		io.WriteString(w, strconv.FormatInt(seq.maxValue, 10))
+	}
+	if seq.cache > 0 {
+		io.WriteString(w, " CACHE ")
+		io.WriteString(w, strconv.FormatInt(seq.cache, 10))
+	}
+	if seq.cycle {
+		io.WriteString(w, " CYCLE")
+	}
+}
+
+// normalize returns a copy of seq with the default values of the omitted options.
+// MariaDB and TiDB use the same default values.
+// https://mariadb.com/kb/en/create-sequence/
+func (seq *Sequence) normalize() *Sequence {
+	tmp := *seq // shallow copy
+	if tmp.increment == 0 {
+		tmp.increment = 1
+	}
+	if !tmp.hasMinValue {
+		tmp.hasMinValue = true
+		if tmp.increment > 0 {
+			tmp.minValue = 1
+		} else {
+			tmp.minValue = math.MinInt64 + 1
+		}
+	}
+	if !tmp.hasMaxValue {
+		tmp.hasMaxValue = true
+		if tmp.increment > 0 {
+			tmp.maxValue = math.MaxInt64 - 1
+		} else {
+			tmp.maxValue = -1
+		}
+	}
+	if !tmp.hasStart {
+		tmp.hasStart = true
+		if tmp.increment > 0 {
+			tmp.start = tmp.minValue
+		} else {
+			tmp.start = tmp.maxValue
+		}
+	}
+	if tmp.cache == 0 {
+		tmp.cache = 1000
+	}
+	return &tmp
+}
+
+// alterSequenceOptions returns the options of ALTER SEQUENCE that change the sequence from to the sequence to.
+// It returns nil if the sequences are equivalent.
+// ALTER SEQUENCE doesn't change the current value of the sequence.
+func alterSequenceOptions(from, to *Sequence) []string {
+	from, to = from.normalize(), to.normalize()
+
+	var opts []string
+	if from.start != to.start {
+		opts = append(opts, "START WITH "+strconv.FormatInt(to.start, 10))
+	}
+	if from.increment != to.increment {
+		opts = append(opts, "INCREMENT BY "+strconv.FormatInt(to.increment, 10))
+	}
+	if from.minValue != to.minValue {
+		opts = append(opts, "MINVALUE "+strconv.FormatInt(to.minValue, 10))
+	}
+	if from.maxValue != to.maxValue {
+		opts = append(opts, "MAXVALUE "+strconv.FormatInt(to.maxValue, 10))
+	}
+	if from.cache != to.cache {
+		opts = append(opts, "CACHE "+strconv.FormatInt(to.cache, 10))
+	}
+	if from.cycle != to.cycle {
+		if to.cycle {
+			opts = append(opts, "CYCLE")
+		} else {
+			opts = append(opts, "NOCYCLE")
+		}
+	}
+	return opts
+}
@@ -92,3 +92,22 @@ var commonInitialisms = map[string]bool{
 	"XSS":   true,
 	"OAuth": true,
 }
+
+func snakeToCamel(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	for _, word := range strings.Split(s, "_") {
+		if word == "" {
+			continue
+		}
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		ch, n := utf8.DecodeRuneInString(word)
+		buf.WriteRune(unicode.ToUpper(ch))
+		buf.WriteString(word[n:])
+	}
+	return buf.String()
+}
@@ -71,3 +71,46 @@ func BenchmarkCamelToSnake(b *testing.B) {
 		camelToSnake("BenchmarkCamelToSnake")
 	}
 }
+
+func TestSnakeToCamel(t *testing.T) {
+	testcases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "",
+			want: "",
+		},
+		{
+			in:   "one",
+			want: "One",
+		},
+		{
+			in:   "id",
+			want: "ID",
+		},
+		{
+			in:   "user_id",
+			want: "UserID",
+		},
+		{
+			in:   "this_https_connection",
+			want: "ThisHTTPSConnection",
+		},
+		{
+			in:   "foo__bar_",
+			want: "FooBar",
+		},
+		{
+			in:   "foo1",
+			want: "Foo1",
+		},
+	}
+
+	for _, tc := range testcases {
+		got := snakeToCamel(tc.in)
+		if got != tc.want {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package myddlmaker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// generateSplitFiles writes the DDL of each table into Config.OutDir,
+// and writes the index file that sources them into Config.OutFilePath.
+func (m *Maker) generateSplitFiles() error {
+	if err := m.parse(); err != nil {
+		return err
+	}
+	files, index, err := m.splitFiles()
+	if err != nil {
+		return err
+	}
+	return m.writeSplitFiles(files, index)
+}
+
+// splitFiles returns the DDL of each table in Config.OutDir and the index file that sources them.
+// The keys of files are the names of the files. m must be parsed.
+func (m *Maker) splitFiles() (files map[string][]byte, index []byte, err error) {
+	// the referenced tables are sourced first.
+	sorted, deferred, err := m.orderedTables()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files = make(map[string][]byte, len(sorted))
+	var buf bytes.Buffer
+	if m.config.Bootstrap {
+		m.generateCreateDatabase(&buf)
+	}
+	if m.config.KeepForeignKeyChecks {
+		if !m.config.SkipDropTable {
+			m.generateDropTables(&buf, sorted)
+		}
+	} else {
+		buf.WriteString("SET foreign_key_checks=0;\n\n")
+	}
+	m.generateSequences(&buf)
+
+	for _, table := range sorted {
+		var tbl bytes.Buffer
+		if !m.config.SkipDropTable && !m.config.KeepForeignKeyChecks {
+			// DROP TABLE of the index file drops the tables if KeepForeignKeyChecks is enabled.
+			fmt.Fprintf(&tbl, "DROP TABLE IF EXISTS %s;\n\n", quote(table.name))
+		}
+		m.generateCreateTable(&tbl, table, m.config.Bootstrap)
+		tbl.WriteString(";\n")
+
+		name, err := splitFileName(m.config.OutDir, table, ".sql")
+		if err != nil {
+			return nil, nil, err
+		}
+		files[name] = tbl.Bytes()
+		fmt.Fprintf(&buf, "SOURCE %s;\n", path.Join(filepath.ToSlash(m.config.OutDir), table.name+".sql"))
+	}
+
+	if m.config.KeepForeignKeyChecks {
+		if len(deferred) > 0 {
+			buf.WriteString("\n")
+			m.generateDeferredForeignKeys(&buf, deferred)
+		}
+	} else {
+		buf.WriteString("\nSET foreign_key_checks=1;\n")
+	}
+	return files, buf.Bytes(), nil
+}
+
+// writeSplitFiles writes the files that splitFiles returns.
+// The index file is written last, because it sources the other files.
+// The files that the previous index file sourced but files doesn't have are removed,
+// e.g. the files of the dropped tables.
+func (m *Maker) writeSplitFiles(files map[string][]byte, index []byte) error {
+	stale, err := m.sourcedFiles()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.config.OutDir, 0o755); err != nil {
+		return fmt.Errorf("myddlmaker: failed to create %q: %w", m.config.OutDir, err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			return fmt.Errorf("myddlmaker: failed to write %q: %w", name, err)
+		}
+	}
+	if err := os.WriteFile(m.config.OutFilePath, index, 0o644); err != nil {
+		return fmt.Errorf("myddlmaker: failed to write %q: %w", m.config.OutFilePath, err)
+	}
+
+	for _, name := range stale {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("myddlmaker: failed to remove %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sourcedFiles returns the files in Config.OutDir that the current index file sources.
+func (m *Maker) sourcedFiles() ([]string, error) {
+	data, err := os.ReadFile(m.config.OutFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("myddlmaker: failed to read %q: %w", m.config.OutFilePath, err)
+	}
+
+	dir := filepath.Clean(m.config.OutDir)
+	var names []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "SOURCE ") || !strings.HasSuffix(line, ";") {
+			continue
+		}
+		name := filepath.Clean(filepath.FromSlash(line[len("SOURCE ") : len(line)-1]))
+		if filepath.Dir(name) == dir && strings.HasSuffix(name, ".sql") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// splitFileName returns the name of the file of the table in dir.
+func splitFileName(dir string, table *table, suffix string) (string, error) {
+	if strings.ContainsRune(table.name, '/') || strings.ContainsRune(table.name, filepath.Separator) {
+		return "", fmt.Errorf("myddlmaker: table %q: the name contains a path separator", table.name)
+	}
+	return filepath.Join(dir, table.name+suffix), nil
+}
+
+// generateGoSplitFiles writes the Go source code of each table into Config.OutGoDir,
+// and writes the declarations that the tables share into Config.OutGoFilePath.
+func (m *Maker) generateGoSplitFiles() error {
+	if err := m.parse(); err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte, len(m.tables)+1)
+	var buf bytes.Buffer
+	m.generateGoHeader(&buf, nil, true)
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	files[filepath.Clean(m.config.OutGoFilePath)] = source
+
+	for _, tbl := range m.tables {
+		buf.Reset()
+		m.generateGoHeader(&buf, []*table{tbl}, false)
+		m.generateGoTable(&buf, tbl)
+		source, err := format.Source(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		name, err := splitFileName(m.config.OutGoDir, tbl, "_gen.go")
+		if err != nil {
+			return err
+		}
+		if _, ok := files[name]; ok {
+			return fmt.Errorf("myddlmaker: table %q: %q conflicts with another file", tbl.name, name)
+		}
+		files[name] = source
+	}
+
+	if err := os.MkdirAll(m.config.OutGoDir, 0o755); err != nil {
+		return fmt.Errorf("myddlmaker: failed to create %q: %w", m.config.OutGoDir, err)
+	}
+	for name, source := range files {
+		if err := os.WriteFile(name, source, 0o644); err != nil {
+			return fmt.Errorf("myddlmaker: failed to write %q: %w", name, err)
+		}
+	}
+	return removeStaleGoFiles(m.config.OutGoDir, files)
+}
+
+// removeStaleGoFiles removes the generated files in dir that files doesn't have,
+// e.g. the files of the dropped tables.
+// The generated files are the files named *_gen.go that start with goHeader.
+func removeStaleGoFiles(dir string, files map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("myddlmaker: failed to read %q: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_gen.go") {
+			continue
+		}
+		name := filepath.Join(dir, entry.Name())
+		if _, ok := files[name]; ok {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("myddlmaker: failed to read %q: %w", name, err)
+		}
+		if !bytes.HasPrefix(data, []byte(goHeader)) {
+			continue
+		}
+		if err := os.Remove(name); err != nil {
+			return fmt.Errorf("myddlmaker: failed to remove %q: %w", name, err)
+		}
+	}
+	return nil
+}
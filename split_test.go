@@ -0,0 +1,291 @@
+package myddlmaker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMaker_GenerateFile_OutDir(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine: "InnoDB",
+		},
+		OutFilePath: filepath.Join(dir, "schema.sql"),
+		OutDir:      filepath.Join(dir, "schema"),
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OrderEntry{}, &Foo1{}, &OrderUser{}, &OrderTeam{})
+	if err := m.GenerateFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the index file sources the files of the tables in the order of the foreign key constraints.
+	schemaDir := filepath.ToSlash(filepath.Join(dir, "schema"))
+	want := "SET foreign_key_checks=0;\n\n" +
+		"SOURCE " + schemaDir + "/team.sql;\n" +
+		"SOURCE " + schemaDir + "/user.sql;\n" +
+		"SOURCE " + schemaDir + "/entry.sql;\n" +
+		"SOURCE " + schemaDir + "/foo1.sql;\n" +
+		"\n" +
+		"SET foreign_key_checks=1;\n"
+	got, err := os.ReadFile(filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("index file is not match: (-want/+got)\n%s", diff)
+	}
+
+	want = "DROP TABLE IF EXISTS `foo1`;\n\n" +
+		"CREATE TABLE `foo1` (\n" +
+		"    `id` INTEGER NOT NULL,\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB;\n"
+	got, err = os.ReadFile(filepath.Join(dir, "schema", "foo1.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("table file is not match: (-want/+got)\n%s", diff)
+	}
+
+	// the cyclic constraint is not deferred, because foreign_key_checks is disabled.
+	got, err = os.ReadFile(filepath.Join(dir, "schema", "team.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "CONSTRAINT `fk_team_owner`") {
+		t.Errorf("want fk_team_owner in team.sql, got:\n%s", got)
+	}
+}
+
+func TestMaker_GenerateFile_OutDirKeepForeignKeyChecks(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(&Config{
+		DB: &DBConfig{
+			Engine: "InnoDB",
+		},
+		OutFilePath:          filepath.Join(dir, "schema.sql"),
+		OutDir:               filepath.Join(dir, "schema"),
+		KeepForeignKeyChecks: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&OrderEntry{}, &OrderUser{}, &OrderTeam{})
+	if err := m.GenerateFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	schemaDir := filepath.ToSlash(filepath.Join(dir, "schema"))
+	want := "DROP TABLE IF EXISTS `entry`, `user`, `team`;\n\n" +
+		"SOURCE " + schemaDir + "/team.sql;\n" +
+		"SOURCE " + schemaDir + "/user.sql;\n" +
+		"SOURCE " + schemaDir + "/entry.sql;\n" +
+		"\n" +
+		"-- the following constraints have cyclic references, they are added after the tables are created.\n" +
+		"ALTER TABLE `team` ADD CONSTRAINT `fk_team_owner` FOREIGN KEY (`owner_id`) REFERENCES `user` (`id`);\n\n"
+	got, err := os.ReadFile(filepath.Join(dir, "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("index file is not match: (-want/+got)\n%s", diff)
+	}
+
+	// the tables are dropped by the index file.
+	want = "CREATE TABLE `team` (\n" +
+		"    `id` INTEGER NOT NULL AUTO_INCREMENT,\n" +
+		"    `owner_id` INTEGER NOT NULL,\n" +
+		"    INDEX `idx_owner_id` (`owner_id`),\n" +
+		"    PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB;\n"
+	got, err = os.ReadFile(filepath.Join(dir, "schema", "team.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("table file is not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateGoFile_OutGoDir(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(&Config{
+		OutGoFilePath: filepath.Join(dir, "schema_gen.go"),
+		OutGoDir:      dir,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo1{}, &Foo33{})
+	if err := m.GenerateGoFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		want    []string
+		notWant []string
+	}{
+		{
+			// the shared declarations are written only once.
+			name:    "schema_gen.go",
+			want:    []string{"\t\"context\"\n\t\"database/sql\"\n", "type execer interface {", "type queryer interface {"},
+			notWant: []string{"func InsertFoo1("},
+		},
+		{
+			name:    "foo1_gen.go",
+			want:    []string{"import (\n\t\"context\"\n)\n", "func InsertFoo1("},
+			notWant: []string{"type execer interface {", "func InsertFoo33("},
+		},
+		{
+			name:    "foo33_gen.go",
+			want:    []string{"\t\"context\"\n\t\"fmt\"\n\t\"strings\"\n", "func InsertFoo33(", "func validateFoo33("},
+			notWant: []string{"type execer interface {", "func InsertFoo1("},
+		},
+	}
+	for _, tt := range tests {
+		got, err := os.ReadFile(filepath.Join(dir, tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(string(got), s) {
+				t.Errorf("want %q in %s, but not found:\n%s", s, tt.name, got)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(string(got), s) {
+				t.Errorf("want no %q in %s, but found:\n%s", s, tt.name, got)
+			}
+		}
+	}
+}
+
+func TestMaker_GenerateFile_OutDirStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutFilePath: filepath.Join(dir, "schema.sql"),
+		OutDir:      filepath.Join(dir, "schema"),
+	}
+	m, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo1{}, &Foo2{})
+	if err := m.GenerateFile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schema", "notes.sql"), []byte("-- notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// foo2 is dropped.
+	m, err = New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo1{})
+	if err := m.GenerateFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	// the files that the index file didn't source are kept.
+	want := []string{"foo1.sql", "notes.sql"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("files are not match: (-want/+got)\n%s", diff)
+	}
+}
+
+func TestMaker_GenerateGoFile_OutGoDirStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		OutGoFilePath: filepath.Join(dir, "schema_gen.go"),
+		OutGoDir:      dir,
+	}
+	m, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo1{}, &Foo33{})
+	if err := m.GenerateGoFile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "helper_gen.go"), []byte("package schema\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// foo33 is dropped.
+	m, err = New(config)
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&Foo1{})
+	if err := m.GenerateGoFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	// the files without the header of the generated code are kept.
+	want := []string{"foo1_gen.go", "helper_gen.go", "schema_gen.go"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("files are not match: (-want/+got)\n%s", diff)
+	}
+}
+
+type SplitPathSeparator struct {
+	ID int32
+}
+
+func (*SplitPathSeparator) Table() string {
+	return "../foo"
+}
+
+func (*SplitPathSeparator) PrimaryKey() *PrimaryKey {
+	return NewPrimaryKey("id")
+}
+
+func TestMaker_GenerateFile_PathSeparator(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(&Config{
+		OutFilePath:   filepath.Join(dir, "schema.sql"),
+		OutDir:        filepath.Join(dir, "schema"),
+		OutGoFilePath: filepath.Join(dir, "schema_gen.go"),
+		OutGoDir:      dir,
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize Maker: %v", err)
+	}
+	m.AddStructs(&SplitPathSeparator{})
+
+	want := `myddlmaker: table "../foo": the name contains a path separator`
+	if err := m.GenerateFile(); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+	if err := m.GenerateGoFile(); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -25,6 +25,117 @@ type Table interface {
 	Table() string
 }
 
+type enumValues interface {
+	EnumValues() []string
+}
+
+type setValues interface {
+	SetValues() []string
+}
+
+type tableOptions interface {
+	TableOptions() *TableOptions
+}
+
+// TableOptions is the options of a table.
+// They override DBConfig.
+// Implement the TableOptions method to customize the options.
+//
+//	func (*User) TableOptions() *myddlmaker.TableOptions {
+//		// ENGINE=InnoDB DEFAULT CHARACTER SET=latin1 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 COMMENT='users'
+//		return myddlmaker.NewTableOptions().
+//			Engine("InnoDB").
+//			Charset("latin1").
+//			RowFormat("COMPRESSED").
+//			KeyBlockSize(8).
+//			Comment("users")
+//	}
+type TableOptions struct {
+	engine          string
+	charset         string
+	collate         string
+	rowFormat       string
+	comment         string
+	autoIncrement   uint64
+	keyBlockSize    int
+	statsPersistent string
+	shardRowIDBits  int
+}
+
+// NewTableOptions returns new empty options.
+func NewTableOptions() *TableOptions {
+	return &TableOptions{}
+}
+
+// Engine returns a copy of opts with the storage engine.
+func (opts *TableOptions) Engine(engine string) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.engine = engine
+	return &tmp
+}
+
+// Charset returns a copy of opts with the default character set.
+func (opts *TableOptions) Charset(charset string) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.charset = charset
+	return &tmp
+}
+
+// Collate returns a copy of opts with the default collation.
+func (opts *TableOptions) Collate(collate string) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.collate = collate
+	return &tmp
+}
+
+// RowFormat returns a copy of opts with the row format, e.g. DYNAMIC and COMPRESSED.
+func (opts *TableOptions) RowFormat(format string) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.rowFormat = format
+	return &tmp
+}
+
+// Comment returns a copy of opts with the comment.
+func (opts *TableOptions) Comment(comment string) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.comment = comment
+	return &tmp
+}
+
+// AutoIncrement returns a copy of opts with the initial value of AUTO_INCREMENT.
+func (opts *TableOptions) AutoIncrement(n uint64) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.autoIncrement = n
+	return &tmp
+}
+
+// KeyBlockSize returns a copy of opts with the page size in kilobytes for compressed tables.
+func (opts *TableOptions) KeyBlockSize(size int) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.keyBlockSize = size
+	return &tmp
+}
+
+// StatsPersistent returns a copy of opts that enables or disables the persistent statistics.
+func (opts *TableOptions) StatsPersistent(enabled bool) *TableOptions {
+	tmp := *opts // shallow copy
+	if enabled {
+		tmp.statsPersistent = "1"
+	} else {
+		tmp.statsPersistent = "0"
+	}
+	return &tmp
+}
+
+// ShardRowIDBits returns a copy of opts with the number of the shard bits of the implicit row id of TiDB.
+// It scatters the rows of the tables without clustered primary keys.
+// It is available only for DialectTiDB.
+func (opts *TableOptions) ShardRowIDBits(n int) *TableOptions {
+	tmp := *opts // shallow copy
+	tmp.shardRowIDBits = n
+	return &tmp
+}
+
 type table struct {
 	name            string
 	rawName         string
@@ -33,11 +144,62 @@ type table struct {
 	indexes         []*Index
 	uniqueIndexes   []*UniqueIndex
 	foreignKeys     []*ForeignKey
+	checks          []*CheckConstraint
 	fullTextIndexes []*FullTextIndex
 	spatialIndexes  []*SpatialIndex
+	partitions      *Partitions
+
+	multiValuedIndexes []*MultiValuedIndex
+
+	// engine, charset and collate override DBConfig.
+	// They are set by the TableOptions method or ParseSQL.
+	engine  string
+	charset string
+	collate string
+
+	// the other table options.
+	rowFormat       string
+	comment         string
+	autoIncrement   uint64
+	keyBlockSize    int
+	statsPersistent string
+	shardRowIDBits  int
+
+	// renamedFrom is the old names of the table.
+	renamedFrom []string
+}
+
+// tableCharset returns the default character set and collation of the table.
+// The table options override db as a whole,
+// because the collation of db may not be compatible with the character set of the table.
+func tableCharset(table *table, db *DBConfig) (charset, collate string) {
+	if table.charset != "" || table.collate != "" || db == nil {
+		return table.charset, table.collate
+	}
+	return db.Charset, db.Collate
+}
+
+// columnCharset returns the character set and collation of the column.
+// They are empty if the column is not a string type.
+func columnCharset(table *table, col *column, db *DBConfig) (charset, collate string) {
+	switch col.typ {
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET":
+	default:
+		return "", ""
+	}
+	if col.charset != "" || col.collate != "" {
+		charset, collate = col.charset, col.collate
+	} else {
+		charset, collate = tableCharset(table, db)
+	}
+	if charset == "" && collate != "" {
+		// the name of a collation starts with the name of its character set.
+		charset, _, _ = strings.Cut(collate, "_")
+	}
+	return charset, collate
 }
 
-func newTable(s any) (*table, error) {
+func newTable(s any, d Dialect) (*table, error) {
 	val := reflect.ValueOf(s)
 	typ := indirect(val.Type())
 	iface := val.Interface()
@@ -53,18 +215,11 @@ func newTable(s any) (*table, error) {
 		tbl.name = camelToSnake(typ.Name())
 	}
 
-	fields := reflect.VisibleFields(typ)
-	tbl.columns = make([]*column, 0, len(fields))
-	for _, f := range fields {
-		col, err := newColumn(f)
-		if err != nil {
-			if !errors.Is(err, errSkipColumn) {
-				return nil, err
-			}
-		} else {
-			tbl.columns = append(tbl.columns, col)
-		}
+	columns, err := newColumns(typ, "", "", d)
+	if err != nil {
+		return nil, err
 	}
+	tbl.columns = columns
 
 	if pk, ok := iface.(primaryKey); ok {
 		tbl.primaryKey = pk.PrimaryKey()
@@ -78,12 +233,36 @@ func newTable(s any) (*table, error) {
 	if idx, ok := iface.(foreignKeys); ok {
 		tbl.foreignKeys = idx.ForeignKeys()
 	}
+	if chk, ok := iface.(checks); ok {
+		tbl.checks = chk.Checks()
+	}
 	if idx, ok := iface.(fullTextIndexes); ok {
 		tbl.fullTextIndexes = idx.FullTextIndexes()
 	}
 	if idx, ok := iface.(spatialIndex); ok {
 		tbl.spatialIndexes = idx.SpatialIndexes()
 	}
+	if idx, ok := iface.(multiValuedIndexes); ok {
+		tbl.multiValuedIndexes = idx.MultiValuedIndexes()
+	}
+	if p, ok := iface.(partitions); ok {
+		tbl.partitions = p.Partitions()
+	}
+	if opts, ok := iface.(tableOptions); ok {
+		o := opts.TableOptions()
+		tbl.engine = o.engine
+		tbl.charset = o.charset
+		tbl.collate = o.collate
+		tbl.rowFormat = o.rowFormat
+		tbl.comment = o.comment
+		tbl.autoIncrement = o.autoIncrement
+		tbl.keyBlockSize = o.keyBlockSize
+		tbl.statsPersistent = o.statsPersistent
+		tbl.shardRowIDBits = o.shardRowIDBits
+	}
+	if r, ok := iface.(renamedFrom); ok {
+		tbl.renamedFrom = r.RenamedFrom()
+	}
 
 	return &tbl, nil
 }
@@ -93,6 +272,7 @@ type column struct {
 	name string
 
 	// rawName is the name in Go codes.
+	// It is the selector from the table struct for the fields of embedded structs, e.g. Timestamps.CreatedAt.
 	rawName string
 
 	// typ is the type name in SQL queries
@@ -101,13 +281,38 @@ type column struct {
 	// rawType is the type name in Go codes.
 	rawType reflect.Type
 
+	// pointer reports whether the type in Go codes is a pointer.
+	pointer bool
+
+	// stringKind reports whether the kind of the type in Go codes is string,
+	// or the type is a pointer to a string kind.
+	stringKind bool
+
 	size int
 
+	// precision and scale are the total number of digits and the number of digits after the decimal point of DECIMAL.
+	precision int
+	scale     int
+
+	// values is the permitted values of ENUM and SET.
+	values []string
+
 	// autoIncr marks the column an auto increment column.
 	autoIncr bool
 
+	// autoRandom marks the column an AUTO_RANDOM column of TiDB.
+	// https://docs.pingcap.com/tidb/stable/auto-random
+	autoRandom bool
+
+	// shardBits is the number of the shard bits of AUTO_RANDOM.
+	// If it is zero, the default of TiDB is used.
+	shardBits int
+
 	unsigned bool
 
+	// json marks the JSON columns that MariaDB stores as LONGTEXT with the JSON_VALID check.
+	json bool
+
 	// invisible marks invisible columns.
 	// https://dev.mysql.com/doc/refman/8.0/en/invisible-columns.html
 	invisible bool
@@ -118,6 +323,15 @@ type column struct {
 	// def is the default value of the column.
 	def string
 
+	// onUpdate is the value that MySQL sets when the row is updated, e.g. CURRENT_TIMESTAMP(6).
+	onUpdate string
+
+	// autoNowAdd and autoNow mark the columns that the database owns.
+	// The default value of them is the current timestamp,
+	// and autoNow columns are also updated to the current timestamp on every update.
+	autoNowAdd bool
+	autoNow    bool
+
 	// comment is a comment
 	comment string
 
@@ -129,10 +343,22 @@ type column struct {
 
 	// srid is the id of spatial reference systems
 	srid int
+
+	// generated is the expression of the generated column.
+	// https://dev.mysql.com/doc/refman/8.0/en/create-table-generated-columns.html
+	generated string
+
+	// stored marks the generated column stored.
+	// The generated columns are virtual by default.
+	stored bool
+
+	// renamedFrom is the old names of the column.
+	renamedFrom []string
 }
 
 var errSkipColumn = errors.New("myddlmaker: skip this column")
 var timeType = reflect.TypeOf(time.Time{})
+var decimalType = reflect.TypeOf(Decimal{})
 var nullTimeType = reflect.TypeOf(sql.NullTime{})
 var nullStringType = reflect.TypeOf(sql.NullString{})
 var nullBoolType = reflect.TypeOf(sql.NullBool{})
@@ -144,100 +370,91 @@ var nullInt64Type = reflect.TypeOf(sql.NullInt64{})
 var jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
 var myddlmakerJSON = reflect.TypeOf((*jsonMarker)(nil)).Elem()
 
-func newColumn(f reflect.StructField) (*column, error) {
-	var invalidType bool
-
-	typ := indirect(f.Type)
-	col := &column{
-		rawType: typ,
-	}
+// newColumns returns the columns of the fields of typ.
+// The fields of embedded structs and the struct fields with the prefix option are flattened into columns.
+// selector is the Go selector of typ from the table struct, and prefix is the prefix of the column names.
+func newColumns(typ reflect.Type, selector, prefix string, d Dialect) ([]*column, error) {
+	columns := make([]*column, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name, remain, _ := strings.Cut(f.Tag.Get(StructTagName), ",")
+		if name == IgnoreName {
+			continue
+		}
 
-	if typ.Implements(myddlmakerJSON) {
-		col.typ = "JSON"
-	}
-	switch typ.Kind() {
-	case reflect.Bool:
-		col.typ = "TINYINT"
-		col.size = 1
-	case reflect.Int8:
-		col.typ = "TINYINT"
-	case reflect.Int16:
-		col.typ = "SMALLINT"
-	case reflect.Int32:
-		col.typ = "INTEGER"
-	case reflect.Int64:
-		col.typ = "BIGINT"
-	case reflect.Uint8:
-		col.typ = "TINYINT"
-		col.unsigned = true
-	case reflect.Uint16:
-		col.typ = "SMALLINT"
-		col.unsigned = true
-	case reflect.Uint32:
-		col.typ = "INTEGER"
-		col.unsigned = true
-	case reflect.Uint64:
-		col.typ = "BIGINT"
-		col.unsigned = true
-	case reflect.Float32:
-		col.typ = "FLOAT"
-	case reflect.Float64:
-		col.typ = "DOUBLE"
-	case reflect.String:
-		col.typ = "VARCHAR"
-		col.size = 191
-	case reflect.Slice:
-		if typ == jsonRawMessageType {
-			col.typ = "JSON"
-		} else if typ.Elem().Kind() == reflect.Uint8 {
-			col.typ = "VARBINARY"
-			col.size = 767
-		} else {
-			invalidType = true
+		embeddedPrefix, hasPrefix := lookupPrefix(remain)
+		if hasPrefix || (f.Anonymous && isEmbeddedStruct(f.Type)) {
+			if f.Type.Kind() == reflect.Pointer {
+				return nil, fmt.Errorf("myddlmaker: field %s%s: embedded pointers are not supported", selector, f.Name)
+			}
+			if !isEmbeddedStruct(f.Type) {
+				return nil, fmt.Errorf("myddlmaker: field %s%s: prefix is available only for struct fields", selector, f.Name)
+			}
+			cols, err := newColumns(f.Type, selector+f.Name+".", prefix+embeddedPrefix, d)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, cols...)
+			continue
 		}
-	case reflect.Array:
-		if typ.Elem().Kind() == reflect.Uint8 {
-			col.typ = "BINARY"
-			col.size = typ.Len()
-		} else {
-			invalidType = true
+		if !f.IsExported() {
+			continue
 		}
-	case reflect.Struct:
-		switch typ {
-		case timeType:
-			col.typ = "DATETIME"
-			col.size = 6
-		case nullTimeType:
-			col.typ = "DATETIME"
-			col.size = 6
-		case nullStringType:
-			col.typ = "VARCHAR"
-			col.size = 191
-		case nullBoolType:
-			col.typ = "TINYINT"
-			col.size = 1
-		case nullByteType:
-			col.typ = "TINYINT"
-			col.unsigned = true
-		case nullFloat64Type:
-			col.typ = "DOUBLE"
-		case nullInt16Type:
-			col.typ = "SMALLINT"
-		case nullInt32Type:
-			col.typ = "INTEGER"
-		case nullInt64Type:
-			col.typ = "BIGINT"
-		default:
-			invalidType = true
+
+		col, err := newColumn(f, d)
+		if err != nil {
+			if errors.Is(err, errSkipColumn) {
+				continue
+			}
+			return nil, err
 		}
-	default:
-		invalidType = true
+		col.name = prefix + col.name
+		col.rawName = selector + col.rawName
+		columns = append(columns, col)
 	}
+	return columns, nil
+}
 
-	if typ.Implements(myddlmakerJSON) {
-		col.typ = "JSON"
-		invalidType = false
+// lookupPrefix returns the value of the prefix option in the tag.
+func lookupPrefix(opts string) (string, bool) {
+	for len(opts) > 0 {
+		var opt string
+		opt, opts, _ = cutComma(opts)
+		if strings.HasPrefix(opt, "prefix=") {
+			return strings.TrimPrefix(opt, "prefix="), true
+		}
 	}
+	return "", false
+}
+
+// isEmbeddedStruct reports whether the fields of typ are flattened into columns.
+// The structs that are mapped into a column, e.g. time.Time, are not.
+func isEmbeddedStruct(typ reflect.Type) bool {
+	typ = indirect(typ)
+	if typ.Kind() != reflect.Struct || typ.Implements(myddlmakerJSON) {
+		return false
+	}
+	switch typ {
+	case timeType, decimalType, nullTimeType, nullStringType, nullBoolType, nullByteType,
+		nullFloat64Type, nullInt16Type, nullInt32Type, nullInt64Type:
+		return false
+	}
+	return true
+}
+
+func newColumn(f reflect.StructField, d Dialect) (*column, error) {
+	typ := indirect(f.Type)
+	col := &column{
+		rawType: typ,
+		pointer: f.Type.Kind() == reflect.Pointer,
+	}
+	col.stringKind = typ.Kind() == reflect.String && (f.Type == typ || f.Type.Elem() == typ)
+
+	ok, err := d.mapType(col, typ)
+	if err != nil {
+		return nil, err
+	}
+	invalidType := !ok
 
 	// parse the tag of the field.
 	col.rawName = f.Name
@@ -256,8 +473,18 @@ func newColumn(f reflect.StructField) (*column, error) {
 			col.null = true
 		case "auto":
 			col.autoIncr = true
+		case "auto_random":
+			col.autoRandom = true
 		case "invisible":
 			col.invisible = true
+		case "stored":
+			col.stored = true
+		case "virtual":
+			col.stored = false
+		case "auto_now_add":
+			col.autoNowAdd = true
+		case "auto_now":
+			col.autoNow = true
 		default:
 			name, val, _ := strings.Cut(opt, "=")
 			switch name {
@@ -267,6 +494,16 @@ func newColumn(f reflect.StructField) (*column, error) {
 					return nil, fmt.Errorf("myddlmaker: failed to parse size param in tag: %w", err)
 				}
 				col.size = int(v)
+			case "auto_random":
+				v, err := strconv.ParseInt(val, 10, 0)
+				if err != nil {
+					return nil, fmt.Errorf("myddlmaker: failed to parse auto_random param in tag: %w", err)
+				}
+				if v < 1 {
+					return nil, fmt.Errorf("myddlmaker: the shard bits of auto_random must be positive, but got %d", v)
+				}
+				col.autoRandom = true
+				col.shardBits = int(v)
 			case "srid":
 				v, err := strconv.ParseInt(val, 10, 0)
 				if err != nil {
@@ -277,7 +514,31 @@ func newColumn(f reflect.StructField) (*column, error) {
 				col.typ = val
 				col.unsigned = false
 				col.size = 0
+				col.precision = 0
+				col.scale = 0
+				col.values = nil
+				if col.json {
+					// the character set of JSON documents in MariaDB.
+					col.charset = ""
+					col.collate = ""
+					col.json = false
+				}
 				invalidType = false
+				if err := parseTypeArgs(col); err != nil {
+					return nil, fmt.Errorf("myddlmaker: failed to parse type param in tag: %w", err)
+				}
+			case "precision":
+				v, err := strconv.ParseInt(val, 10, 0)
+				if err != nil {
+					return nil, fmt.Errorf("myddlmaker: failed to parse precision param in tag: %w", err)
+				}
+				col.precision = int(v)
+			case "scale":
+				v, err := strconv.ParseInt(val, 10, 0)
+				if err != nil {
+					return nil, fmt.Errorf("myddlmaker: failed to parse scale param in tag: %w", err)
+				}
+				col.scale = int(v)
 			case "default":
 				col.def = val
 			case "charset":
@@ -286,10 +547,49 @@ func newColumn(f reflect.StructField) (*column, error) {
 				col.collate = val
 			case "comment":
 				col.comment = val
+			case "renamed_from":
+				col.renamedFrom = append(col.renamedFrom, val)
+			case "as":
+				col.generated = trimParens(val)
 			}
 		}
 	}
 
+	if col.autoNowAdd || col.autoNow {
+		// the precision of the current timestamp must match the size of the column.
+		if col.def != "" {
+			return nil, fmt.Errorf("myddlmaker: column %q can't have both auto_now and the default value", col.name)
+		}
+		col.def = currentTimestamp(col.size)
+		if col.autoNow {
+			col.onUpdate = col.def
+		}
+	}
+
+	if col.autoRandom {
+		if col.autoIncr {
+			return nil, fmt.Errorf("myddlmaker: column %q can't be both auto and auto_random", col.name)
+		}
+		if col.def != "" {
+			return nil, fmt.Errorf("myddlmaker: auto_random column %q can't have the default value", col.name)
+		}
+	}
+
+	if col.generated != "" {
+		if col.autoIncr {
+			return nil, fmt.Errorf("myddlmaker: generated column %q can't be auto increment", col.name)
+		}
+		if col.autoRandom {
+			return nil, fmt.Errorf("myddlmaker: generated column %q can't be auto_random", col.name)
+		}
+		if col.def != "" {
+			return nil, fmt.Errorf("myddlmaker: generated column %q can't have the default value", col.name)
+		}
+		if col.onUpdate != "" {
+			return nil, fmt.Errorf("myddlmaker: generated column %q can't be auto_now", col.name)
+		}
+	}
+
 	if invalidType {
 		return nil, fmt.Errorf("myddlmaker: unknown type: %s", typ.String())
 	}
@@ -297,6 +597,40 @@ func newColumn(f reflect.StructField) (*column, error) {
 	return col, nil
 }
 
+// currentTimestamp returns CURRENT_TIMESTAMP with the fractional seconds precision.
+func currentTimestamp(fsp int) string {
+	if fsp == 0 {
+		return "CURRENT_TIMESTAMP"
+	}
+	return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", fsp)
+}
+
+// parseCurrentTimestamp parses CURRENT_TIMESTAMP and its synonyms, and returns the fractional seconds precision.
+// ok is false if expr is not the current timestamp.
+func parseCurrentTimestamp(expr string) (fsp int, ok bool) {
+	name, args, hasArgs := strings.Cut(strings.ToUpper(strings.TrimSpace(expr)), "(")
+	switch name {
+	case "CURRENT_TIMESTAMP", "NOW", "LOCALTIME", "LOCALTIMESTAMP":
+	default:
+		return 0, false
+	}
+	if !hasArgs {
+		return 0, name != "NOW"
+	}
+	if !strings.HasSuffix(args, ")") {
+		return 0, false
+	}
+	args = strings.TrimSpace(strings.TrimSuffix(args, ")"))
+	if args == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(args)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
 func indirect(typ reflect.Type) reflect.Type {
 	seen := map[reflect.Type]struct{}{
 		typ: {},
@@ -312,10 +646,101 @@ func indirect(typ reflect.Type) reflect.Type {
 	return typ
 }
 
-func cutComma(s string) (before string, after string, found bool) {
+// parseTypeArgs parses the arguments of the types in col.typ
+// that have their own fields in column, e.g. ENUM('active','inactive') and DECIMAL(10,2).
+// The trailing UNSIGNED of DECIMAL and the floating-point types and CHARACTER SET and COLLATE of ENUM and SET are parsed too.
+// The other types and the types with other attributes, e.g. ZEROFILL, are not changed.
+func parseTypeArgs(col *column) error {
+	name, _, _ := strings.Cut(col.typ, "(")
+	if fields := strings.Fields(name); len(fields) > 0 {
+		name = fields[0]
+	}
+	switch strings.ToUpper(name) {
+	case "ENUM", "SET", "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL":
+	default:
+		return nil
+	}
+	raw := *col
+	p, err := newDDLParser(col.typ)
+	if err != nil {
+		return err
+	}
+	if err := p.parseDataType(col); err != nil {
+		return err
+	}
+	values := col.typ == "ENUM" || col.typ == "SET"
+	for {
+		switch {
+		case p.peek(0).kind == tokenEOF:
+			return nil
+		case p.acceptKeyword("UNSIGNED"):
+			col.unsigned = true
+		case p.acceptKeyword("SIGNED"):
+			col.unsigned = false
+		case values && (p.acceptKeyword("CHARACTER", "SET") || p.acceptKeyword("CHARSET")):
+			charset, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			col.charset = charset
+		case values && p.acceptKeyword("COLLATE"):
+			collate, err := p.identifier()
+			if err != nil {
+				return err
+			}
+			col.collate = collate
+		default:
+			// pass the type through as is.
+			*col = raw
+			return nil
+		}
+	}
+}
+
+// trimParens removes the parentheses that enclose whole s.
+func trimParens(s string) string {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return s
+	}
 	var cnt int
 	for i, b := range s {
 		switch b {
+		case '(':
+			cnt++
+		case ')':
+			cnt--
+			if cnt == 0 && i != len(s)-1 {
+				// the first parenthesis is closed in the middle of s, e.g. "(a) + (b)".
+				return s
+			}
+		}
+	}
+	return s[1 : len(s)-1]
+}
+
+// cutComma slices s around the first comma that is not in parentheses nor string literals.
+// A single quote starts a string literal only at the beginning of a value, e.g. default='a,b',
+// so the apostrophes in comments are not string literals.
+func cutComma(s string) (before string, after string, found bool) {
+	var cnt int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			if i > 0 && !strings.ContainsRune("=(,", rune(s[i-1])) {
+				continue
+			}
+			// skip the string literal.
+			for i++; i < len(s); i++ {
+				if s[i] == '\\' {
+					i++
+				} else if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
 		case '(':
 			cnt++
 		case ')':
@@ -82,7 +82,7 @@ func TestTable(t *testing.T) {
 			{name: "uint16", rawName: "Uint16", typ: "SMALLINT", unsigned: true},
 			{name: "uint32", rawName: "Uint32", typ: "INTEGER", unsigned: true},
 			{name: "uint64", rawName: "Uint64", typ: "BIGINT", unsigned: true},
-			{name: "string", rawName: "String", typ: "VARCHAR", size: 191},
+			{name: "string", rawName: "String", typ: "VARCHAR", size: 191, stringKind: true},
 			{name: "bool", rawName: "Bool", typ: "TINYINT", size: 1},
 			{name: "bytes", rawName: "Bytes", typ: "VARBINARY", size: 767},
 			{name: "byte_array", rawName: "ByteArray", typ: "BINARY", size: 4},
@@ -91,12 +91,12 @@ func TestTable(t *testing.T) {
 			{name: "custom_type", rawName: "CustomType", typ: "TIMESTAMP"},
 			{name: "decimal", rawName: "Decimal", typ: "DECIMAL"},
 			{name: "numeric", rawName: "Numeric", typ: "NUMERIC"},
-			{name: "decimal_with_precision_and_scale", rawName: "DecimalWithPrecisionAndScale", typ: "DECIMAL(9,6)"},
-			{name: "numeric_with_precision_and_scale", rawName: "NumericWithPrecisionAndScale", typ: "NUMERIC(9,6)"},
-			{name: "decimal_with_precision", rawName: "DecimalWithPrecision", typ: "DECIMAL(9)"},
-			{name: "numeric_with_precision", rawName: "NumericWithPrecision", typ: "NUMERIC(9)"},
-			{name: "p_int8", rawName: "PInt8", typ: "TINYINT"},
-			{name: "p_p_int8", rawName: "PPInt8", typ: "TINYINT"},
+			{name: "decimal_with_precision_and_scale", rawName: "DecimalWithPrecisionAndScale", typ: "DECIMAL", precision: 9, scale: 6},
+			{name: "numeric_with_precision_and_scale", rawName: "NumericWithPrecisionAndScale", typ: "NUMERIC", precision: 9, scale: 6},
+			{name: "decimal_with_precision", rawName: "DecimalWithPrecision", typ: "DECIMAL", precision: 9},
+			{name: "numeric_with_precision", rawName: "NumericWithPrecision", typ: "NUMERIC", precision: 9},
+			{name: "p_int8", rawName: "PInt8", typ: "TINYINT", pointer: true},
+			{name: "p_p_int8", rawName: "PPInt8", typ: "TINYINT", pointer: true},
 			{name: "fuga", rawName: "Hoge", typ: "INTEGER"},
 			{name: "time", rawName: "Time", typ: "DATETIME", size: 6},
 			{name: "null_time", rawName: "NullTime", typ: "DATETIME", size: 6},
@@ -111,11 +111,11 @@ func TestTable(t *testing.T) {
 			{name: "default_value", rawName: "DefaultValue", typ: "BIGINT", def: "123"},
 		},
 	}
-	got, err := newTable(&FooBar{})
+	got, err := newTable(&FooBar{}, DialectMySQL)
 	if err != nil {
 		t.Fatal(err)
 	}
-	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, ForeignKey{})
+	opt1 := cmp.AllowUnexported(table{}, column{}, PrimaryKey{}, Index{}, UniqueIndex{}, keyPart{}, ForeignKey{})
 	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
 	if diff := cmp.Diff(want, got, opt1, opt2); diff != "" {
 		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
@@ -129,12 +129,118 @@ func TestTable_UnknownType(t *testing.T) {
 		Foo customType
 	}
 
-	_, err := newTable(&FooBar{})
+	_, err := newTable(&FooBar{}, DialectMySQL)
 	if err == nil {
 		t.Error("want some errors, got nil")
 	}
 }
 
+func TestTable_TypeAttributes(t *testing.T) {
+	type FooBar struct {
+		Price    float64 `ddl:",type=DECIMAL(10,2) UNSIGNED"`
+		Discount float64 `ddl:",type=NUMERIC(5,2) unsigned"`
+		Status   string  `ddl:",type=ENUM('active','inactive') CHARACTER SET latin1 COLLATE latin1_bin"`
+		Flags    string  `ddl:",type=SET('a','b') CHARSET ascii"`
+		Serial   float64 `ddl:",type=DECIMAL(10,2) UNSIGNED ZEROFILL"`
+	}
+	want := []*column{
+		{name: "price", rawName: "Price", typ: "DECIMAL", precision: 10, scale: 2, unsigned: true},
+		{name: "discount", rawName: "Discount", typ: "NUMERIC", precision: 5, scale: 2, unsigned: true},
+		{name: "status", rawName: "Status", typ: "ENUM", values: []string{"active", "inactive"}, stringKind: true, charset: "latin1", collate: "latin1_bin"},
+		{name: "flags", rawName: "Flags", typ: "SET", values: []string{"a", "b"}, stringKind: true, charset: "ascii"},
+
+		// the types with unknown attributes are passed through.
+		{name: "serial", rawName: "Serial", typ: "DECIMAL(10,2) UNSIGNED ZEROFILL"},
+	}
+	got, err := newTable(&FooBar{}, DialectMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt1 := cmp.AllowUnexported(column{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, got.columns, opt1, opt2); diff != "" {
+		t.Errorf("columns are not match (-want/+got):\n%s", diff)
+	}
+}
+
+type Timestamps struct {
+	CreatedAt time.Time `ddl:",auto_now_add"`
+	UpdatedAt time.Time `ddl:",auto_now"`
+}
+
+type Address struct {
+	Street string
+	City   string `ddl:"town"`
+}
+
+type audit struct {
+	Editor string
+	note   string
+}
+
+type FooEmbedded struct {
+	ID int64
+	Timestamps
+	audit
+	Billing  Address `ddl:",prefix=billing_"`
+	Shipping Address `ddl:",prefix=shipping_"`
+	Location Address `ddl:"-"`
+	secret   string
+}
+
+func TestTable_Embedded(t *testing.T) {
+	want := &table{
+		name:    "foo_embedded",
+		rawName: "FooEmbedded",
+		columns: []*column{
+			{name: "id", rawName: "ID", typ: "BIGINT"},
+			{name: "created_at", rawName: "Timestamps.CreatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", autoNowAdd: true},
+			{name: "updated_at", rawName: "Timestamps.UpdatedAt", typ: "DATETIME", size: 6, def: "CURRENT_TIMESTAMP(6)", onUpdate: "CURRENT_TIMESTAMP(6)", autoNow: true},
+			{name: "editor", rawName: "audit.Editor", typ: "VARCHAR", size: 191, stringKind: true},
+			{name: "billing_street", rawName: "Billing.Street", typ: "VARCHAR", size: 191, stringKind: true},
+			{name: "billing_town", rawName: "Billing.City", typ: "VARCHAR", size: 191, stringKind: true},
+			{name: "shipping_street", rawName: "Shipping.Street", typ: "VARCHAR", size: 191, stringKind: true},
+			{name: "shipping_town", rawName: "Shipping.City", typ: "VARCHAR", size: 191, stringKind: true},
+		},
+	}
+	got, err := newTable(&FooEmbedded{}, DialectMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt1 := cmp.AllowUnexported(table{}, column{})
+	opt2 := cmpopts.IgnoreFields(column{}, "rawType")
+	if diff := cmp.Diff(want, got, opt1, opt2); diff != "" {
+		t.Errorf("table structures are not match (-want/+got):\n%s", diff)
+	}
+}
+
+func TestTable_EmbeddedError(t *testing.T) {
+	type EmbeddedPointer struct {
+		ID int64
+		*Timestamps
+	}
+	if _, err := newTable(&EmbeddedPointer{}, DialectMySQL); err == nil {
+		t.Error("want an error for embedded pointers, got nil")
+	}
+
+	type PrefixedString struct {
+		ID   int64
+		Name string `ddl:",prefix=foo_"`
+	}
+	if _, err := newTable(&PrefixedString{}, DialectMySQL); err == nil {
+		t.Error("want an error for the prefix option of a string field, got nil")
+	}
+}
+
+func TestTable_AutoRandomError(t *testing.T) {
+	type ZeroShardBits struct {
+		ID int64 `ddl:",auto_random=0"`
+	}
+	if _, err := newTable(&ZeroShardBits{}, DialectTiDB); err == nil {
+		t.Error("want an error for auto_random=0, got nil")
+	}
+}
+
 func TestCutComma(t *testing.T) {
 	tests := []struct {
 		in     string
@@ -196,6 +302,31 @@ func TestCutComma(t *testing.T) {
 			after:  "null",
 			found:  true,
 		},
+		{
+			in:     "default='a,b',null",
+			before: "default='a,b'",
+			after:  "null",
+			found:  true,
+		},
+		{
+			in:     "default='it''s, ok',null",
+			before: "default='it''s, ok'",
+			after:  "null",
+			found:  true,
+		},
+		{
+			in:     "type=ENUM('a,b','c'),null",
+			before: "type=ENUM('a,b','c')",
+			after:  "null",
+			found:  true,
+		},
+		{
+			// the apostrophe doesn't start a string literal.
+			in:     "comment=user's name,null",
+			before: "comment=user's name",
+			after:  "null",
+			found:  true,
+		},
 	}
 
 	for i, tt := range tests {
@@ -211,3 +342,24 @@ func TestCutComma(t *testing.T) {
 		}
 	}
 }
+
+func TestTrimParens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "a + b", want: "a + b"},
+		{in: "(a + b)", want: "a + b"},
+		{in: "((a + b))", want: "(a + b)"},
+		{in: "(a) + (b)", want: "(a) + (b)"},
+		{in: "JSON_EXTRACT(doc, '$.id')", want: "JSON_EXTRACT(doc, '$.id')"},
+		{in: "(JSON_EXTRACT(doc, '$.id'))", want: "JSON_EXTRACT(doc, '$.id')"},
+	}
+
+	for _, tt := range tests {
+		got := trimParens(tt.in)
+		if got != tt.want {
+			t.Errorf("trimParens(%q): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
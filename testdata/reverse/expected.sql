@@ -0,0 +1,71 @@
+SET foreign_key_checks=0;
+
+DROP TABLE IF EXISTS `user`;
+
+CREATE TABLE `user` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(191) NOT NULL,
+    `nickname` VARCHAR(64) NULL COMMENT 'nickname shown in the profile',
+    `email` VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    `active` TINYINT(1) NOT NULL DEFAULT 1,
+    `status` ENUM('active','banned') NOT NULL DEFAULT 'active',
+    `age` TINYINT UNSIGNED NULL,
+    `score` INTEGER NULL,
+    `rank` MEDIUMINT UNSIGNED NOT NULL,
+    `balance` DECIMAL(9,2) NOT NULL DEFAULT 0,
+    `rate` DOUBLE NOT NULL,
+    `price` DECIMAL(10,2) UNSIGNED NOT NULL,
+    `cost` NUMERIC(10,2) UNSIGNED NULL,
+    `ratio` DOUBLE UNSIGNED NULL,
+    `weight` FLOAT UNSIGNED NOT NULL,
+    `token` BINARY(16) NOT NULL,
+    `avatar` VARBINARY(767) NULL,
+    `profile` JSON NOT NULL,
+    `bio` TEXT NOT NULL,
+    `birthday` DATE NULL,
+    `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+    `deleted_at` DATETIME(6) NULL INVISIBLE,
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;
+
+
+DROP TABLE IF EXISTS `entry`;
+
+CREATE TABLE `entry` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `title` VARCHAR(191) NOT NULL,
+    `body` TEXT NOT NULL,
+    INDEX `idx_user_id` (`user_id`, `title`) COMMENT 'entries of the user',
+    UNIQUE `uniq_title` (`title`) INVISIBLE,
+    FULLTEXT INDEX `idx_body` (`body`) WITH PARSER ngram,
+    CONSTRAINT `fk_entry_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `chk_entry_title` CHECK (`title` <> ''),
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin;
+
+
+DROP TABLE IF EXISTS `entry_tags`;
+
+CREATE TABLE `entry_tags` (
+    `entry_id` BIGINT UNSIGNED NOT NULL,
+    `tag` VARCHAR(64) NOT NULL,
+    CONSTRAINT `fk_entry_tags_entry` FOREIGN KEY (`entry_id`) REFERENCES `entry` (`id`),
+    PRIMARY KEY (`entry_id`, `tag`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 STATS_PERSISTENT=0 COMMENT='tags of entries';
+
+
+DROP TABLE IF EXISTS `access_log`;
+
+CREATE TABLE `access_log` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(6) NOT NULL,
+    PRIMARY KEY (`id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET=utf8mb4 DEFAULT COLLATE=utf8mb4_bin
+PARTITION BY RANGE COLUMNS (`created_at`) (
+    PARTITION `p2022` VALUES LESS THAN ('2023-01-01 00:00:00'),
+    PARTITION `pmax` VALUES LESS THAN (MAXVALUE) COMMENT 'the rest'
+);
+
+SET foreign_key_checks=1;
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+
+	"github.com/shogo82148/myddlmaker"
+	schema "github.com/shogo82148/myddlmaker/testdata/reverse"
+)
+
+func main() {
+	m, err := myddlmaker.New(&myddlmaker.Config{
+		DB: &myddlmaker.DBConfig{
+			Engine:  "InnoDB",
+			Charset: "utf8mb4",
+			Collate: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	m.AddStructs(&schema.User{}, &schema.Entry{}, &schema.EntryTags{}, &schema.AccessLog{})
+
+	if err := m.GenerateFile(); err != nil {
+		log.Fatal(err)
+	}
+	if err := m.GenerateGoFile(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/shogo82148/myddlmaker"
+)
+
+type User struct {
+	ID        uint64 `ddl:",auto"`
+	Name      string
+	Nickname  sql.NullString     `ddl:",null,size=64,comment=nickname shown in the profile"`
+	Email     string             `ddl:",size=255,charset=ascii,collate=ascii_bin"`
+	Active    bool               `ddl:",default=1"`
+	Status    string             `ddl:",type=ENUM('active','banned'),default='active'"`
+	Age       sql.NullByte       `ddl:",null"`
+	Score     sql.NullInt32      `ddl:",null"`
+	Rank      uint32             `ddl:",type=MEDIUMINT UNSIGNED"`
+	Balance   myddlmaker.Decimal `ddl:",precision=9,scale=2,default=0"`
+	Rate      float64
+	Price     myddlmaker.Decimal `ddl:",type=DECIMAL(10,2) UNSIGNED"`
+	Cost      sql.NullFloat64    `ddl:",null,type=NUMERIC(10,2) UNSIGNED"`
+	Ratio     sql.NullFloat64    `ddl:",null,type=DOUBLE UNSIGNED"`
+	Weight    float32            `ddl:",type=FLOAT UNSIGNED"`
+	Token     [16]byte
+	Avatar    []byte `ddl:",null"`
+	Profile   json.RawMessage
+	Bio       string       `ddl:",type=TEXT"`
+	Birthday  sql.NullTime `ddl:",null,type=DATE"`
+	CreatedAt time.Time    `ddl:",auto_now_add"`
+	UpdatedAt time.Time    `ddl:",auto_now"`
+	DeletedAt sql.NullTime `ddl:",null,invisible"`
+}
+
+func (*User) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*User) TableOptions() *myddlmaker.TableOptions {
+	return myddlmaker.NewTableOptions().
+		Engine("InnoDB").
+		Charset("utf8mb4").
+		Collate("utf8mb4_bin")
+}
+
+type Entry struct {
+	ID     uint64 `ddl:",auto"`
+	UserID uint64
+	Title  string
+	Body   string `ddl:",type=TEXT"`
+}
+
+func (*Entry) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id")
+}
+
+func (*Entry) Indexes() []*myddlmaker.Index {
+	return []*myddlmaker.Index{
+		myddlmaker.NewIndex("idx_user_id", "user_id", "title").Comment("entries of the user"),
+	}
+}
+
+func (*Entry) UniqueIndexes() []*myddlmaker.UniqueIndex {
+	return []*myddlmaker.UniqueIndex{
+		myddlmaker.NewUniqueIndex("uniq_title", "title").Invisible(),
+	}
+}
+
+func (*Entry) FullTextIndexes() []*myddlmaker.FullTextIndex {
+	return []*myddlmaker.FullTextIndex{
+		myddlmaker.NewFullTextIndex("idx_body", "body").WithParser("ngram"),
+	}
+}
+
+func (*Entry) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_entry_user", []string{"user_id"}, "user", []string{"id"}).OnDelete(myddlmaker.ForeignKeyOptionCascade),
+	}
+}
+
+func (*Entry) Checks() []*myddlmaker.CheckConstraint {
+	return []*myddlmaker.CheckConstraint{
+		myddlmaker.NewCheck("chk_entry_title", "`title` <> ''"),
+	}
+}
+
+func (*Entry) TableOptions() *myddlmaker.TableOptions {
+	return myddlmaker.NewTableOptions().
+		Engine("InnoDB").
+		Charset("utf8mb4").
+		Collate("utf8mb4_bin")
+}
+
+type EntryTags struct {
+	EntryID uint64
+	Tag     string `ddl:",size=64"`
+}
+
+func (*EntryTags) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("entry_id", "tag")
+}
+
+func (*EntryTags) ForeignKeys() []*myddlmaker.ForeignKey {
+	return []*myddlmaker.ForeignKey{
+		myddlmaker.NewForeignKey("fk_entry_tags_entry", []string{"entry_id"}, "entry", []string{"id"}),
+	}
+}
+
+func (*EntryTags) TableOptions() *myddlmaker.TableOptions {
+	return myddlmaker.NewTableOptions().
+		Engine("InnoDB").
+		Charset("utf8mb4").
+		Collate("utf8mb4_bin").
+		RowFormat("COMPRESSED").
+		KeyBlockSize(8).
+		StatsPersistent(false).
+		Comment("tags of entries")
+}
+
+type AccessLog struct {
+	ID        uint64 `ddl:",auto"`
+	CreatedAt time.Time
+}
+
+func (*AccessLog) PrimaryKey() *myddlmaker.PrimaryKey {
+	return myddlmaker.NewPrimaryKey("id", "created_at")
+}
+
+func (*AccessLog) TableOptions() *myddlmaker.TableOptions {
+	return myddlmaker.NewTableOptions().
+		Engine("InnoDB").
+		Charset("utf8mb4").
+		Collate("utf8mb4_bin")
+}
+
+func (*AccessLog) Partitions() *myddlmaker.Partitions {
+	return myddlmaker.NewRangeColumnsPartitions("created_at").Add(
+		myddlmaker.NewPartition("p2022").LessThan("'2023-01-01 00:00:00'"),
+		myddlmaker.NewPartition("pmax").LessThan("MAXVALUE").Comment("the rest"),
+	)
+}
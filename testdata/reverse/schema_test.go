@@ -0,0 +1,22 @@
+package schema
+
+import (
+	"os"
+	"testing"
+)
+
+// TestRoundTrip checks that schema.go generated by myddlmaker.GenerateStructs from expected.sql
+// generates the same DDL.
+func TestRoundTrip(t *testing.T) {
+	want, err := os.ReadFile("expected.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(want) != string(got) {
+		t.Errorf("ddl is not match:\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -3,6 +3,8 @@ package myddlmaker
 import (
 	"fmt"
 	"log"
+	"reflect"
+	"strings"
 )
 
 type validationError struct {
@@ -16,6 +18,15 @@ func (e *validationError) Error() string {
 type validator struct {
 	SkipValidationFKIndex bool
 
+	// DB is the default options of the tables.
+	DB *DBConfig
+
+	// Dialect rejects the features that the database doesn't support.
+	Dialect Dialect
+
+	// Sequences are the sequences that Maker.AddSequences added.
+	Sequences []*Sequence
+
 	tables []*table
 	errs   []string
 
@@ -38,11 +49,17 @@ func (v *validator) Validate() error {
 	v.createTableMap()
 
 	for _, table := range v.tables {
+		v.validateColumns(table)
 		v.validateIndex(table)
 		v.validateIndexName(table)
+		v.validateMultiValuedIndexes(table)
 	}
 	v.validateConstraints()
 	v.validateForeignKeys()
+	v.validatePartitions()
+	if v.Dialect != nil {
+		v.Dialect.validate(v)
+	}
 
 	if err := v.Err(); err != nil {
 		return err
@@ -97,6 +114,138 @@ func (v *validator) createTableMap() {
 	v.columnMap = columns
 }
 
+// validateSequences validates the names and the ranges of the sequences.
+// Sequences share the namespace with tables.
+func (v *validator) validateSequences() {
+	seen := make(map[string]struct{}, len(v.Sequences))
+	for _, seq := range v.Sequences {
+		if _, ok := v.tableMap[seq.name]; ok {
+			v.SaveErrorf("sequence %q: duplicated name of table: %q", seq.name, seq.name)
+			continue
+		}
+		if _, ok := seen[seq.name]; ok {
+			v.SaveErrorf("duplicated name of sequence: %q", seq.name)
+			continue
+		}
+		seen[seq.name] = struct{}{}
+
+		if seq.hasMinValue && seq.hasMaxValue && seq.minValue >= seq.maxValue {
+			v.SaveErrorf("sequence %q: MINVALUE %d must be less than MAXVALUE %d", seq.name, seq.minValue, seq.maxValue)
+			continue
+		}
+		if seq.hasStart && seq.hasMinValue && seq.start < seq.minValue {
+			v.SaveErrorf("sequence %q: START WITH %d is less than MINVALUE %d", seq.name, seq.start, seq.minValue)
+		}
+		if seq.hasStart && seq.hasMaxValue && seq.start > seq.maxValue {
+			v.SaveErrorf("sequence %q: START WITH %d is greater than MAXVALUE %d", seq.name, seq.start, seq.maxValue)
+		}
+	}
+}
+
+func (v *validator) validateColumns(table *table) {
+	for _, col := range table.columns {
+		v.validateDecimal(table, col)
+		v.validateValues(table, col)
+		v.validateCurrentTimestamp(table, col)
+	}
+}
+
+// validateCurrentTimestamp validates DEFAULT CURRENT_TIMESTAMP and ON UPDATE CURRENT_TIMESTAMP.
+// https://dev.mysql.com/doc/refman/8.0/en/timestamp-initialization.html
+func (v *validator) validateCurrentTimestamp(table *table, col *column) {
+	if (col.autoNowAdd || col.autoNow) && col.typ != "DATETIME" && col.typ != "TIMESTAMP" {
+		v.SaveErrorf("table %q, column %q: auto_now and auto_now_add are available only for DATETIME and TIMESTAMP", table.name, col.name)
+		return
+	}
+
+	if fsp, ok := parseCurrentTimestamp(col.def); ok && (col.typ == "DATETIME" || col.typ == "TIMESTAMP") && fsp != col.size {
+		v.SaveErrorf("table %q, column %q: the precision of the default value %s doesn't match the size %d", table.name, col.name, col.def, col.size)
+	}
+	if col.onUpdate == "" {
+		return
+	}
+	if col.typ != "DATETIME" && col.typ != "TIMESTAMP" {
+		v.SaveErrorf("table %q, column %q: ON UPDATE is available only for DATETIME and TIMESTAMP", table.name, col.name)
+		return
+	}
+	fsp, ok := parseCurrentTimestamp(col.onUpdate)
+	if !ok {
+		v.SaveErrorf("table %q, column %q: ON UPDATE %s is not the current timestamp", table.name, col.name, col.onUpdate)
+		return
+	}
+	if fsp != col.size {
+		v.SaveErrorf("table %q, column %q: the precision of ON UPDATE %s doesn't match the size %d", table.name, col.name, col.onUpdate, col.size)
+	}
+}
+
+// validateDecimal validates the precision and the scale of DECIMAL columns.
+// https://dev.mysql.com/doc/refman/8.0/en/fixed-point-types.html
+func (v *validator) validateDecimal(table *table, col *column) {
+	if col.typ != "DECIMAL" && col.typ != "NUMERIC" {
+		if col.precision != 0 || col.scale != 0 {
+			v.SaveErrorf("table %q, column %q: precision and scale are available only for DECIMAL", table.name, col.name)
+		}
+		return
+	}
+	if col.precision == 0 && col.scale == 0 {
+		return
+	}
+	if col.precision < 1 || col.precision > 65 {
+		v.SaveErrorf("table %q, column %q: precision must be between 1 and 65, but got %d", table.name, col.name, col.precision)
+	}
+	if col.scale < 0 || col.scale > 30 {
+		v.SaveErrorf("table %q, column %q: scale must be between 0 and 30, but got %d", table.name, col.name, col.scale)
+	} else if col.scale > col.precision {
+		v.SaveErrorf("table %q, column %q: scale %d must not be greater than precision %d", table.name, col.name, col.scale, col.precision)
+	}
+}
+
+// validateValues validates the permitted values of ENUM and SET columns.
+func (v *validator) validateValues(table *table, col *column) {
+	if col.typ != "ENUM" && col.typ != "SET" {
+		return
+	}
+	if len(col.values) == 0 {
+		v.SaveErrorf("table %q, column %q: %s requires at least one value", table.name, col.name, col.typ)
+		return
+	}
+	if col.typ == "SET" && len(col.values) > 64 {
+		v.SaveErrorf("table %q, column %q: SET can have at most 64 values", table.name, col.name)
+	}
+
+	seen := map[string]struct{}{}
+	for _, val := range col.values {
+		if _, ok := seen[val]; ok {
+			v.SaveErrorf("table %q, column %q: duplicated value of %s: %q", table.name, col.name, col.typ, val)
+			continue
+		}
+		seen[val] = struct{}{}
+		if col.typ == "SET" && strings.Contains(val, ",") {
+			v.SaveErrorf("table %q, column %q: value of SET can't contain commas: %q", table.name, col.name, val)
+		}
+	}
+
+	// the default value must be one of the values.
+	tokens, err := tokenize(col.def)
+	if err != nil || len(tokens) != 2 || tokens[0].kind != tokenString {
+		// it is not a string literal, e.g. NULL.
+		return
+	}
+	def := []string{tokens[0].val}
+	if col.typ == "SET" {
+		def = strings.Split(tokens[0].val, ",")
+		if tokens[0].val == "" {
+			def = nil
+		}
+	}
+	for _, d := range def {
+		if _, ok := seen[d]; !ok {
+			v.SaveErrorf("table %q, column %q: default value %s is not a value of %s", table.name, col.name, col.def, col.typ)
+			break
+		}
+	}
+}
+
 func (v *validator) validateIndex(table *table) {
 	// check existence of the column in the primary key
 	for _, col := range table.primaryKey.columns {
@@ -109,7 +258,7 @@ func (v *validator) validateIndex(table *table) {
 
 	for _, idx := range table.indexes {
 		// check existence of the column in the index
-		for _, col := range idx.columns {
+		for _, col := range v.keyPartColumns(table, idx.keyParts) {
 			name := [2]string{table.name, col}
 			if _, ok := v.columnMap[name]; !ok {
 				v.SaveErrorf("table %q, index %q: column %q not found", table.name, idx.name, col)
@@ -120,7 +269,7 @@ func (v *validator) validateIndex(table *table) {
 
 	for _, idx := range table.uniqueIndexes {
 		// check existence of the column in the unique index
-		for _, col := range idx.columns {
+		for _, col := range v.keyPartColumns(table, idx.keyParts) {
 			name := [2]string{table.name, col}
 			if _, ok := v.columnMap[name]; !ok {
 				v.SaveErrorf("table %q, unique index %q: column %q not found", table.name, idx.name, col)
@@ -130,6 +279,33 @@ func (v *validator) validateIndex(table *table) {
 	}
 }
 
+// keyPartColumns returns the columns used by the key parts, including the columns in functional key parts.
+func (v *validator) keyPartColumns(table *table, parts []*keyPart) []string {
+	var cols []string
+	for _, part := range parts {
+		if part.err != nil {
+			// the names that the parser can't read, e.g. "user-name", worked as plain column names.
+			if _, ok := v.columnMap[[2]string{table.name, part.column}]; ok {
+				cols = append(cols, part.column)
+				continue
+			}
+			v.SaveErrorf("table %q: invalid key part %q: %v", table.name, part.column, part.err)
+			continue
+		}
+		if part.column != "" {
+			cols = append(cols, part.column)
+			continue
+		}
+		exprCols, err := v.exprColumns(table, part.expr)
+		if err != nil {
+			v.SaveErrorf("table %q: invalid key part %q: %v", table.name, part.String(), err)
+			continue
+		}
+		cols = append(cols, exprCols...)
+	}
+	return cols
+}
+
 func (v *validator) validateIndexName(table *table) {
 	seen := map[string]struct{}{}
 
@@ -164,9 +340,118 @@ func (v *validator) validateIndexName(table *table) {
 		}
 		seen[idx.name] = struct{}{}
 	}
+
+	for _, idx := range table.multiValuedIndexes {
+		if _, ok := seen[idx.name]; ok {
+			v.SaveErrorf("table %q: duplicated name of index: %q", table.name, idx.name)
+			continue
+		}
+		seen[idx.name] = struct{}{}
+	}
+}
+
+// castArrayTypes are the types that CAST(... AS type ARRAY) accepts.
+var castArrayTypes = map[string]struct{}{
+	"BINARY":   {},
+	"CHAR":     {},
+	"DATE":     {},
+	"DATETIME": {},
+	"DECIMAL":  {},
+	"SIGNED":   {},
+	"TIME":     {},
+	"UNSIGNED": {},
+}
+
+func (v *validator) validateMultiValuedIndexes(table *table) {
+	for _, idx := range table.multiValuedIndexes {
+		col, ok := v.columnMap[[2]string{table.name, idx.column}]
+		if !ok {
+			v.SaveErrorf("table %q, multi-valued index %q: column %q not found", table.name, idx.name, idx.column)
+			continue
+		}
+		if col.typ != "JSON" && !col.json {
+			v.SaveErrorf("table %q, multi-valued index %q: column %q is not JSON", table.name, idx.name, idx.column)
+			continue
+		}
+
+		typ := castTypeName(idx.typ)
+		if _, ok := castArrayTypes[typ]; !ok {
+			v.SaveErrorf("table %q, multi-valued index %q: invalid type %q", table.name, idx.name, idx.typ)
+			continue
+		}
+
+		if idx.path != "$" {
+			// we don't know the type of the nested values.
+			continue
+		}
+		elem, ok := jsonArrayElem(col.rawType)
+		if !ok {
+			v.SaveErrorf("table %q, multi-valued index %q: column %q is not an array", table.name, idx.name, idx.column)
+			continue
+		}
+		if elem != nil && !castTypeMatches(typ, elem) {
+			v.SaveErrorf("table %q, multi-valued index %q: type %q doesn't match the element type %s of column %q",
+				table.name, idx.name, idx.typ, elem, idx.column)
+		}
+	}
+}
+
+// castTypeName returns the name of the type without its length and precision,
+// e.g. "CHAR" for "CHAR(64)" and "SIGNED" for "SIGNED INTEGER".
+func castTypeName(typ string) string {
+	name, _, _ := strings.Cut(typ, "(")
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if fields := strings.Fields(name); len(fields) == 2 && (fields[1] == "INTEGER" || fields[1] == "INT") {
+		name = fields[0]
+	}
+	return name
+}
+
+// jsonArrayElem returns the type of the elements of JSON[T] where T is a slice or an array.
+// It returns nil if the type of the elements is unknown, e.g. json.RawMessage and JSON[any].
+// ok is false if T is not a slice nor an array.
+func jsonArrayElem(typ reflect.Type) (elem reflect.Type, ok bool) {
+	if !typ.Implements(myddlmakerJSON) || typ.Kind() != reflect.Array {
+		return nil, true
+	}
+	t := indirect(typ.Elem())
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil, true
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as a base64 string.
+			return nil, false
+		}
+		elem := indirect(t.Elem())
+		if elem.Kind() == reflect.Interface {
+			return nil, true
+		}
+		return elem, true
+	}
+	return nil, false
+}
+
+// castTypeMatches reports whether the values of the Go type elem can be cast to typ.
+func castTypeMatches(typ string, elem reflect.Type) bool {
+	if elem == timeType {
+		return typ == "DATE" || typ == "DATETIME" || typ == "TIME" || typ == "CHAR"
+	}
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return typ == "SIGNED"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typ == "UNSIGNED"
+	case reflect.Float32, reflect.Float64:
+		return typ == "DECIMAL"
+	case reflect.String:
+		return typ == "CHAR" || typ == "BINARY"
+	}
+	return false
 }
 
 func (v *validator) validateConstraints() {
+	// the names of the constraints must be unique in the schema.
 	seen := map[string]struct{}{}
 
 	for _, table := range v.tables {
@@ -177,6 +462,13 @@ func (v *validator) validateConstraints() {
 			}
 			seen[fk.name] = struct{}{}
 		}
+		for _, chk := range table.checks {
+			if _, ok := seen[chk.name]; ok {
+				v.SaveErrorf("table %q: duplicated name of check constraint: %q", table.name, chk.name)
+				continue
+			}
+			seen[chk.name] = struct{}{}
+		}
 	}
 }
 
@@ -230,13 +522,18 @@ func (v *validator) validateFKRef(table *table, fk *ForeignKey) {
 			// just ignore it
 			continue
 		}
-		if refcol.typ != mycol.typ || refcol.unsigned != mycol.unsigned || refcol.rawType != mycol.rawType {
+		if refcol.typ != mycol.typ || refcol.unsigned != mycol.unsigned || refcol.rawType != mycol.rawType ||
+			refcol.precision != mycol.precision || refcol.scale != mycol.scale {
 			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q type mismatch", table.name, fk.name, mycol.name, ref.name, col)
 		}
-		if refcol.charset != mycol.charset {
+
+		// the character sets and the collations may be inherited from the tables.
+		refCharset, refCollate := columnCharset(ref, refcol, v.DB)
+		myCharset, myCollate := columnCharset(table, mycol, v.DB)
+		if refCharset != myCharset {
 			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q character set mismatch", table.name, fk.name, mycol.name, ref.name, col)
-		}
-		if refcol.collate != mycol.collate {
+		} else if refCollate != "" && myCollate != "" && refCollate != myCollate {
+			// an empty collation is the default collation of the character set, we don't know what it is.
 			v.SaveErrorf("table %q, foreign key %q: column %q and referenced column %q.%q collate mismatch", table.name, fk.name, mycol.name, ref.name, col)
 		}
 	}
@@ -248,19 +545,138 @@ func (v *validator) validateFKRef(table *table, fk *ForeignKey) {
 	}
 }
 
+func (v *validator) validatePartitions() {
+	// the tables referenced by foreign keys
+	referenced := map[string]struct{}{}
+	for _, table := range v.tables {
+		for _, fk := range table.foreignKeys {
+			referenced[fk.table] = struct{}{}
+		}
+	}
+
+	for _, table := range v.tables {
+		p := table.partitions
+		if p == nil {
+			continue
+		}
+
+		// MySQL requires that every unique key includes all columns in the partitioning expression.
+		// https://dev.mysql.com/doc/refman/8.0/en/partitioning-limitations-partitioning-keys-unique-keys.html
+		for _, col := range v.partitionColumns(table, p) {
+			name := [2]string{table.name, col}
+			if _, ok := v.columnMap[name]; !ok {
+				v.SaveErrorf("table %q, partitions: column %q not found", table.name, col)
+				continue
+			}
+			if !contains(table.primaryKey.columns, col) {
+				v.SaveErrorf("table %q, partitions: column %q must be included in primary key", table.name, col)
+			}
+			for _, idx := range table.uniqueIndexes {
+				if !contains(keyPartColumns(idx.keyParts), col) {
+					v.SaveErrorf("table %q, partitions: column %q must be included in unique index %q", table.name, col, idx.name)
+				}
+			}
+		}
+
+		if (p.typ == "RANGE" || p.typ == "LIST") && len(p.definitions) == 0 {
+			v.SaveErrorf("table %q, partitions: %s partitioning requires partition definitions", table.name, p.typ)
+		}
+		if (p.typ == "RANGE" || p.typ == "LIST") && p.linear {
+			v.SaveErrorf("table %q, partitions: %s partitioning can't be linear", table.name, p.typ)
+		}
+		for _, def := range p.definitions {
+			switch {
+			case p.typ == "RANGE" && def.op != "LESS THAN":
+				v.SaveErrorf("table %q, partitions: partition %q requires VALUES LESS THAN", table.name, def.name)
+			case p.typ == "LIST" && def.op != "IN":
+				v.SaveErrorf("table %q, partitions: partition %q requires VALUES IN", table.name, def.name)
+			case (p.typ == "HASH" || p.typ == "KEY") && def.op != "":
+				v.SaveErrorf("table %q, partitions: partition %q can't have VALUES in %s partitioning", table.name, def.name, p.typ)
+			}
+		}
+
+		// InnoDB doesn't support foreign keys, FULLTEXT indexes and SPATIAL indexes on partitioned tables.
+		// https://dev.mysql.com/doc/refman/8.0/en/partitioning-limitations.html
+		if len(table.foreignKeys) > 0 {
+			v.SaveErrorf("table %q, partitions: partitioned table can't have foreign keys", table.name)
+		}
+		if _, ok := referenced[table.name]; ok {
+			v.SaveErrorf("table %q, partitions: partitioned table can't be referenced by foreign keys", table.name)
+		}
+		if len(table.fullTextIndexes) > 0 {
+			v.SaveErrorf("table %q, partitions: partitioned table can't have FULLTEXT indexes", table.name)
+		}
+		if len(table.spatialIndexes) > 0 {
+			v.SaveErrorf("table %q, partitions: partitioned table can't have SPATIAL indexes", table.name)
+		}
+	}
+}
+
+// partitionColumns returns the columns used by the partitioning.
+func (v *validator) partitionColumns(table *table, p *Partitions) []string {
+	if p.expr == "" {
+		return p.columns
+	}
+	cols, err := v.exprColumns(table, p.expr)
+	if err != nil {
+		v.SaveErrorf("table %q, partitions: invalid expression %q: %v", table.name, p.expr, err)
+		return nil
+	}
+	return cols
+}
+
+// exprColumns returns the columns in the expression.
+// The quoted identifiers are always considered as columns,
+// and the bare words are considered as columns only if the table has them.
+func (v *validator) exprColumns(table *table, expr string) ([]string, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	var cols []string
+	for i, tok := range tokens {
+		if tok.kind != tokenWord && tok.kind != tokenQuotedIdent {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].kind == tokenSymbol && tokens[i+1].val == "(" {
+			// it is a function name.
+			continue
+		}
+		if tok.kind == tokenWord {
+			if _, ok := v.columnMap[[2]string{table.name, tok.val}]; !ok {
+				// it is a keyword or a number.
+				continue
+			}
+		}
+		if !contains(cols, tok.val) {
+			cols = append(cols, tok.val)
+		}
+	}
+	return cols, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *validator) hasIndex(table *table, cols []string) bool {
 	if v.hasPrefix(table.primaryKey.columns, cols) {
 		return true
 	}
 
 	for _, idx := range table.indexes {
-		if v.hasPrefix(idx.columns, cols) {
+		if v.hasPrefix(leadingColumns(idx.keyParts), cols) {
 			return true
 		}
 	}
 
 	for _, idx := range table.uniqueIndexes {
-		if v.hasPrefix(idx.columns, cols) {
+		if v.hasPrefix(leadingColumns(idx.keyParts), cols) {
 			return true
 		}
 	}